@@ -0,0 +1,158 @@
+package caps
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkgset"
+)
+
+type Command struct {
+	printStandard bool
+	identifiers   bool
+	json          bool
+}
+
+func (*Command) Name() string     { return "caps" }
+func (*Command) Synopsis() string { return "Print capabilities of packages." }
+func (*Command) Usage() string {
+	return `caps <expr>:
+	Print capabilities used by packages.
+
+	Capabilities are detected from imports of sensitive packages:
+
+		exec     os/exec
+		files    os, io/ioutil
+		net      net, net/...
+		plugin   plugin
+		reflect  reflect
+		syscall  syscall, golang.org/x/sys/unix, golang.org/x/sys/windows
+		unsafe   unsafe
+
+	With -identifiers the used identifiers are checked instead of imports,
+	e.g. using os.Getenv does not grant files, however os.WriteFile does.
+
+	Direct capabilities are used by the package itself, transitive
+	capabilities are reached via non-std dependencies.
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.identifiers, "identifiers", false, "detect capabilities from used identifiers")
+	f.BoolVar(&cmd.json, "json", false, "print as json")
+}
+
+// Package describes capabilities of a package.
+type Package struct {
+	ID         string
+	Direct     []pkgset.Capability `json:",omitempty"`
+	Transitive []pkgset.Capability `json:",omitempty"`
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.Calc(ctx, f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	direct := map[string][]pkgset.Capability{}
+	holders := map[pkgset.Capability]pkgset.Set{}
+	for _, c := range pkgset.Capabilities {
+		holders[c] = pkgset.New()
+	}
+	result.WalkAllDependencies(func(p *packages.Package) {
+		if pkgset.IsStd(p) {
+			if _, ok := result[p.ID]; !ok {
+				return
+			}
+		}
+
+		caps := pkgset.ImportCapabilities(p)
+		if cmd.identifiers {
+			var err error
+			caps, err = pkgset.IdentifierCapabilities(p)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err.Error())
+			}
+		}
+		direct[p.ID] = caps
+
+		if !pkgset.IsStd(p) {
+			for _, c := range caps {
+				holders[c][p.ID] = p
+			}
+		}
+	})
+
+	transitive := map[string][]pkgset.Capability{}
+	for _, c := range pkgset.Capabilities {
+		for id := range pkgset.Reach(result, holders[c]) {
+			transitive[id] = append(transitive[id], c)
+		}
+	}
+
+	var pkgs []Package
+	for _, p := range result.Sorted() {
+		pkgs = append(pkgs, Package{
+			ID:         p.ID,
+			Direct:     direct[p.ID],
+			Transitive: transitive[p.ID],
+		})
+	}
+
+	if cmd.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(pkgs); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	defer func() { _ = w.Flush() }()
+
+	fmt.Fprint(w, "ID")
+	for _, c := range pkgset.Capabilities {
+		fmt.Fprintf(w, "\t%v", c)
+	}
+	fmt.Fprintln(w)
+	for _, p := range pkgs {
+		fmt.Fprint(w, p.ID)
+		for _, c := range pkgset.Capabilities {
+			switch {
+			case slices.Contains(p.Direct, c):
+				fmt.Fprint(w, "\t●")
+			case slices.Contains(p.Transitive, c):
+				fmt.Fprint(w, "\t○")
+			default:
+				fmt.Fprint(w, "\t·")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "● direct, ○ transitive")
+
+	return subcommands.ExitSuccess
+}
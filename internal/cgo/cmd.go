@@ -0,0 +1,154 @@
+package cgo
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/stat"
+)
+
+type Command struct {
+	printStandard bool
+}
+
+func (*Command) Name() string     { return "cgo" }
+func (*Command) Synopsis() string { return "Print native code dependencies." }
+func (*Command) Usage() string {
+	return `cgo <expr>:
+	Print packages using cgo, their native source files and
+	the system libraries they link based on #cgo directives.
+
+	Additionally it compares the expression with cgo_enabled=0(expr)
+	and prints packages that cannot be built without cgo.
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+}
+
+// noCgo is the setting for loading packages with CGO_ENABLED=0.
+const noCgo = "cgo_enabled=0"
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	expr := f.Args()
+	if len(expr) == 0 {
+		expr = []string{"."}
+	}
+
+	root, err := pkgset.Parse(ctx, expr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	result, err := pkgset.Eval(ctx, root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	nocgo, err := pkgset.Eval(ctx, root, noCgo)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+		nocgo = pkgset.Subtract(nocgo, pkgset.Std())
+	}
+
+	cgo, err := pkgset.Cgo(result)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer func() { _ = w.Flush() }()
+
+	fmt.Fprintln(w, "ID\tC\tC++\tObjC\tFortran\tSWIG\tHeaders\tLibraries")
+	for _, p := range cgo.Sorted() {
+		info, _, errs := stat.Package(p)
+		directives, directiveErrs := stat.CgoDirectivesOf(p)
+		for _, err := range append(errs, directiveErrs...) {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+
+		native := info.Cgo
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", p.ID,
+			native.C, native.CXX, native.ObjC, native.Fortran, native.SWIG, native.Headers,
+			strings.Join(stat.CgoLibraries(directives), " "))
+	}
+
+	// Packages that fail to load without cgo, but load with cgo.
+	unbuildable := pkgset.New()
+	nocgoAll := pkgset.NewAll(nocgo)
+	missing := map[string]bool{}
+	for id, p := range pkgset.NewAll(result) {
+		if len(p.Errors) > 0 || (!cmd.printStandard && pkgset.IsStd(p)) {
+			continue
+		}
+		without, ok := nocgoAll[id]
+		switch {
+		case !ok:
+			missing[p.PkgPath] = true
+		case len(without.Errors) > 0 || len(without.GoFiles) == 0:
+			unbuildable[id] = without
+		}
+	}
+
+	// Packages may be missing due to patterns skipping them or
+	// due to their importers failing, hence load them explicitly.
+	if len(missing) > 0 {
+		loaded, err := pkgset.LoadWith(ctx, []string{noCgo}, slices.Sorted(maps.Keys(missing))...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+		for id, p := range loaded {
+			if len(p.Errors) > 0 || len(p.GoFiles) == 0 {
+				unbuildable[id] = p
+			}
+			nocgoAll[id] = p
+		}
+	}
+	if len(unbuildable) == 0 {
+		return subcommands.ExitSuccess
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "unbuildable with CGO_ENABLED=0:")
+	for _, p := range unbuildable.Sorted() {
+		reason := "no Go files"
+		if len(p.Errors) > 0 {
+			reason = p.Errors[0].Msg
+		}
+		fmt.Fprintf(w, "  %v\t%v\n", p.ID, reason)
+	}
+
+	affected := pkgset.Subtract(pkgset.Reach(nocgoAll, unbuildable), unbuildable)
+	if !cmd.printStandard {
+		affected = pkgset.Subtract(affected, pkgset.Std())
+	}
+	if len(affected) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "importing unbuildable packages:")
+		for _, p := range affected.Sorted() {
+			fmt.Fprintf(w, "  %v\n", p.ID)
+		}
+	}
+
+	return subcommands.ExitSuccess
+}
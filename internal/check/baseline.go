@@ -0,0 +1,146 @@
+package check
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// BaselineEntry is a known violating edge.
+//
+// Lines are not part of the entry, so that unrelated edits do not
+// invalidate the baseline.
+type BaselineEntry struct {
+	Importer string `json:",omitempty"`
+	Imported string
+	// File is relative to the baseline directory.
+	File string `json:",omitempty"`
+}
+
+// Baseline is a set of known violations.
+type Baseline map[BaselineEntry]bool
+
+// ReadBaseline reads baseline entries from a JSON file.
+func ReadBaseline(filename string) (Baseline, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []BaselineEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	baseline := Baseline{}
+	for _, entry := range entries {
+		baseline[entry] = true
+	}
+	return baseline, nil
+}
+
+// WriteBaseline writes entries for violations as sorted JSON.
+func WriteBaseline(filename string, violations []Violation) error {
+	baseline := Baseline{}
+	dir := filepath.Dir(filename)
+	for _, violation := range violations {
+		for _, entry := range violation.entries(dir) {
+			baseline[entry] = true
+		}
+	}
+
+	data, err := json.MarshalIndent(baseline.Sorted(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
+
+// Sorted returns entries sorted by importer, imported and file.
+func (baseline Baseline) Sorted() []BaselineEntry {
+	entries := make([]BaselineEntry, 0, len(baseline))
+	for entry := range baseline {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b BaselineEntry) int {
+		if c := strings.Compare(a.Importer, b.Importer); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Imported, b.Imported); c != 0 {
+			return c
+		}
+		return strings.Compare(a.File, b.File)
+	})
+	return entries
+}
+
+// Filter removes known violations and returns the remaining violations
+// and the baseline entries that no longer occur.
+func (baseline Baseline) Filter(dir string, violations []Violation) (remaining []Violation, fixed []BaselineEntry) {
+	seen := Baseline{}
+	for _, violation := range violations {
+		entries := violation.entries(dir)
+		for _, entry := range entries {
+			seen[entry] = true
+		}
+
+		if len(violation.Sites) == 0 || violation.Importer == nil {
+			if !baseline[entries[0]] {
+				remaining = append(remaining, violation)
+			}
+			continue
+		}
+
+		// Keep only sites that are not in the baseline.
+		sites := violation.Sites[:0:0]
+		for i, site := range violation.Sites {
+			if !baseline[entries[i]] {
+				sites = append(sites, site)
+			}
+		}
+		if len(sites) > 0 {
+			violation.Sites = sites
+			remaining = append(remaining, violation)
+		}
+	}
+
+	for _, entry := range baseline.Sorted() {
+		if !seen[entry] {
+			fixed = append(fixed, entry)
+		}
+	}
+	return remaining, fixed
+}
+
+// entries returns baseline entries for the violation,
+// one for each import site.
+func (violation Violation) entries(dir string) []BaselineEntry {
+	entry := BaselineEntry{Imported: violation.Imported.ID}
+	// Expression violations are about the package itself,
+	// so the import location is not relevant.
+	if violation.Importer == nil || len(violation.Sites) == 0 {
+		if violation.Importer != nil {
+			entry.Importer = violation.Importer.ID
+		}
+		return []BaselineEntry{entry}
+	}
+	entry.Importer = violation.Importer.ID
+
+	entries := make([]BaselineEntry, 0, len(violation.Sites))
+	for _, site := range violation.Sites {
+		entry.File = relativePath(dir, site.File)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func relativePath(dir, file string) string {
+	if absdir, err := filepath.Abs(dir); err == nil {
+		if rel, err := filepath.Rel(absdir, file); err == nil {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
@@ -0,0 +1,36 @@
+package check
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/stat"
+)
+
+func TestBaselineFilter(t *testing.T) {
+	a := &packages.Package{ID: "example.com/a"}
+	b := &packages.Package{ID: "example.com/b"}
+	c := &packages.Package{ID: "example.com/c"}
+
+	violations := []Violation{
+		{Importer: a, Imported: b, Sites: []stat.ImportSite{
+			{Path: b.ID, File: "/src/a/a.go"},
+			{Path: b.ID, File: "/src/a/new.go"},
+		}},
+		{Imported: c},
+	}
+	baseline := Baseline{
+		{Importer: "example.com/a", Imported: "example.com/b", File: "a/a.go"}: true,
+		{Importer: "example.com/a", Imported: "example.com/c", File: "a/a.go"}: true,
+		{Imported: "example.com/c"}: true,
+	}
+
+	remaining, fixed := baseline.Filter("/src", violations)
+	if len(remaining) != 1 || len(remaining[0].Sites) != 1 || remaining[0].Sites[0].File != "/src/a/new.go" {
+		t.Errorf("expected only new.go site to remain, got %+v", remaining)
+	}
+	if len(fixed) != 1 || fixed[0].Imported != "example.com/c" || fixed[0].Importer != "example.com/a" {
+		t.Errorf("expected a -> c to be fixed, got %+v", fixed)
+	}
+}
@@ -0,0 +1,297 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/pkgset/ast"
+	"github.com/loov/goda/internal/stat"
+)
+
+// Violation is a dependency that breaks a rule.
+type Violation struct {
+	Rule *Rule
+
+	// Importer is the importer of the site edge, see Sites.
+	// It is nil for expression rules.
+	Importer *packages.Package
+	// Imported is the denied package.
+	Imported *packages.Package
+
+	// Chain is the shortest import chain from the rule source to Imported.
+	// For expression rules the chain starts from the checked packages.
+	Chain []*packages.Package
+	// Sites are the import specs of the site edge, which is the last edge
+	// in Chain, where the importer is not a std package. Hence, for
+	// "a -> net/http -> crypto/md5" it's the import of net/http in a.
+	Sites []stat.ImportSite
+}
+
+// Direct returns whether Importer directly imports Imported.
+func (violation Violation) Direct() bool {
+	n := len(violation.Chain)
+	return violation.Importer != nil && n >= 2 && violation.Chain[n-2] == violation.Importer
+}
+
+// ChainString formats the chain as "a -> b -> c".
+func (violation Violation) ChainString() string {
+	ids := make([]string, 0, len(violation.Chain))
+	for _, p := range violation.Chain {
+		ids = append(ids, p.ID)
+	}
+	return strings.Join(ids, " -> ")
+}
+
+// Checker evaluates rules using shared loaded packages.
+type Checker struct {
+	Context context.Context
+	Cache   *pkgset.Cache
+	// Scope limits packages considered by allow-only rules.
+	Scope pkgset.Set
+
+	sites map[*packages.Package][]stat.ImportSite
+	// scopeParent is the shortest path tree from Scope.
+	scopeParent map[*packages.Package]*packages.Package
+}
+
+// NewChecker creates a checker for the scope expression.
+func NewChecker(ctx context.Context, scope []string) (*Checker, error) {
+	cache := pkgset.NewCache()
+	result, err := pkgset.CalcCached(ctx, cache, scope)
+	if err != nil {
+		return nil, err
+	}
+	return &Checker{
+		Context: ctx,
+		Cache:   cache,
+		Scope:   result,
+		sites:   map[*packages.Package][]stat.ImportSite{},
+	}, nil
+}
+
+// Check returns violations of rule.
+func (checker *Checker) Check(rule *Rule) ([]Violation, error) {
+	if rule.Expr != nil {
+		result, err := checker.calc(rule.Expr)
+		if err != nil {
+			return nil, err
+		}
+		var violations []Violation
+		for _, p := range result.Sorted() {
+			violation := Violation{Rule: rule, Imported: p}
+			violation.Chain = chainTo(checker.scopeTree(), p)
+			_, violation.Sites = checker.siteEdge(violation.Chain)
+			violations = append(violations, violation)
+		}
+		return violations, nil
+	}
+
+	if rule.Kind == DenyModule {
+		to := pkgset.New()
+		for _, p := range pkgset.NewAll(checker.Scope) {
+			if p.Module != nil && slices.Contains(rule.Modules, p.Module.Path) {
+				to[p.ID] = p
+			}
+		}
+		return checker.deny(rule, checker.Scope, to), nil
+	}
+
+	from, err := checker.calc(rule.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := checker.calc(rule.To)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rule.Kind {
+	case Deny:
+		return checker.deny(rule, from, to), nil
+	case AllowOnly:
+		return checker.allowOnly(rule, from, to), nil
+	default:
+		return nil, fmt.Errorf("unknown rule kind %q", rule.Kind)
+	}
+}
+
+func (checker *Checker) calc(expr ast.Expr) (pkgset.Set, error) {
+	return pkgset.EvalCached(checker.Context, checker.Cache, expr)
+}
+
+// deny finds edges entering `to` that are reachable from `from`.
+func (checker *Checker) deny(rule *Rule, from, to pkgset.Set) []Violation {
+	parent := map[*packages.Package]*packages.Package{}
+	visited := map[*packages.Package]bool{}
+
+	var queue []*packages.Package
+	for _, p := range from.Sorted() {
+		if _, ok := to[p.ID]; ok {
+			continue
+		}
+		visited[p] = true
+		queue = append(queue, p)
+	}
+
+	var violations []Violation
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+
+		for _, dep := range sortedImports(p) {
+			if _, ok := to[dep.ID]; ok {
+				violations = append(violations, Violation{
+					Rule:     rule,
+					Importer: p,
+					Imported: dep,
+					Chain:    append(chainTo(parent, p), dep),
+				})
+				continue
+			}
+			if !visited[dep] {
+				visited[dep] = true
+				parent[dep] = p
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	for i := range violations {
+		violations[i].Importer, violations[i].Sites = checker.siteEdge(violations[i].Chain)
+	}
+	sortViolations(violations)
+	return violations
+}
+
+// allowOnly finds direct imports of `to` from outside of `from`.
+func (checker *Checker) allowOnly(rule *Rule, from, to pkgset.Set) []Violation {
+	var violations []Violation
+	for _, p := range pkgset.NewAll(checker.Scope).Sorted() {
+		if pkgset.IsStd(p) {
+			continue
+		}
+		if _, ok := from[p.ID]; ok {
+			continue
+		}
+		if _, ok := to[p.ID]; ok {
+			continue
+		}
+		for _, dep := range sortedImports(p) {
+			if _, ok := to[dep.ID]; ok {
+				violations = append(violations, Violation{
+					Rule:     rule,
+					Importer: p,
+					Imported: dep,
+					Chain:    []*packages.Package{p, dep},
+					Sites:    checker.sitesOf(p, dep),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// scopeTree returns the shortest path tree from packages in Scope.
+func (checker *Checker) scopeTree() map[*packages.Package]*packages.Package {
+	if checker.scopeParent != nil {
+		return checker.scopeParent
+	}
+
+	parent := map[*packages.Package]*packages.Package{}
+	visited := map[*packages.Package]bool{}
+	queue := checker.Scope.Sorted()
+	for _, p := range queue {
+		visited[p] = true
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, dep := range sortedImports(p) {
+			if !visited[dep] {
+				visited[dep] = true
+				parent[dep] = p
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	checker.scopeParent = parent
+	return parent
+}
+
+// siteEdge returns the importer and import specs of the last edge in chain,
+// where the importer is not std, so the location points to code that can
+// be changed. When all importers are std, the first edge is used.
+func (checker *Checker) siteEdge(chain []*packages.Package) (*packages.Package, []stat.ImportSite) {
+	for i := len(chain) - 1; i >= 1; i-- {
+		if i == 1 || !pkgset.IsStd(chain[i-1]) {
+			return chain[i-1], checker.sitesOf(chain[i-1], chain[i])
+		}
+	}
+	return nil, nil
+}
+
+// sitesOf returns import specs in p that import dep.
+func (checker *Checker) sitesOf(p, dep *packages.Package) []stat.ImportSite {
+	sites, ok := checker.sites[p]
+	if !ok {
+		sites, _ = stat.ImportSitesOf(p)
+		checker.sites[p] = sites
+	}
+
+	var result []stat.ImportSite
+	for _, site := range sites {
+		if imported, ok := p.Imports[site.Path]; ok && imported.ID == dep.ID {
+			result = append(result, site)
+		}
+	}
+	return result
+}
+
+func chainTo(parent map[*packages.Package]*packages.Package, p *packages.Package) []*packages.Package {
+	var chain []*packages.Package
+	for ; p != nil; p = parent[p] {
+		chain = append(chain, p)
+	}
+	slices.Reverse(chain)
+	return chain
+}
+
+func sortedImports(p *packages.Package) []*packages.Package {
+	deps := make([]*packages.Package, 0, len(p.Imports))
+	for _, dep := range p.Imports {
+		deps = append(deps, dep)
+	}
+	slices.SortFunc(deps, func(a, b *packages.Package) int { return compareID(a, b) })
+	return deps
+}
+
+func sortViolations(violations []Violation) {
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		if c := compareID(a.Importer, b.Importer); c != 0 {
+			return c
+		}
+		return compareID(a.Imported, b.Imported)
+	})
+}
+
+func compareID(a, b *packages.Package) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case a.ID < b.ID:
+		return -1
+	case a.ID > b.ID:
+		return 1
+	}
+	return 0
+}
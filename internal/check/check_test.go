@@ -0,0 +1,40 @@
+package check
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/stat"
+)
+
+func TestDenySiteEdge(t *testing.T) {
+	md5 := &packages.Package{ID: "crypto/md5", PkgPath: "crypto/md5"}
+	http := &packages.Package{ID: "net/http", PkgPath: "net/http", Imports: map[string]*packages.Package{"crypto/md5": md5}}
+	a := &packages.Package{ID: "example.com/a", PkgPath: "example.com/a", Imports: map[string]*packages.Package{"net/http": http}}
+
+	checker := &Checker{sites: map[*packages.Package][]stat.ImportSite{
+		a:    {{Path: "net/http", File: "a.go", Line: 3}},
+		http: {{Path: "crypto/md5", File: "http.go", Line: 5}},
+	}}
+
+	rule := &Rule{Kind: Deny}
+	violations := checker.deny(rule, pkgset.NewRoot(a), pkgset.NewRoot(md5))
+	if len(violations) != 1 {
+		t.Fatalf("expected 1 violation, got %d", len(violations))
+	}
+
+	violation := violations[0]
+	if violation.Importer != a || violation.Imported != md5 || violation.Direct() {
+		t.Errorf("expected indirect violation from %v, got %v -> %v", a.ID, violation.Importer.ID, violation.Imported.ID)
+	}
+	if len(violation.Sites) != 1 || violation.Sites[0].File != "a.go" {
+		t.Errorf("expected site in a.go, got %v", violation.Sites)
+	}
+
+	entries := violation.entries(".")
+	if len(entries) != 1 || entries[0].Importer != a.ID || entries[0].File != "a.go" {
+		t.Errorf("unexpected baseline entries %v", entries)
+	}
+}
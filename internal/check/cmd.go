@@ -0,0 +1,185 @@
+package check
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/subcommands"
+)
+
+type Command struct {
+	rules         string
+	baseline      string
+	writeBaseline bool
+	format        string
+}
+
+func (*Command) Name() string     { return "check" }
+func (*Command) Synopsis() string { return "Check dependency rules." }
+func (*Command) Usage() string {
+	return `check [-rules goda.rules] <expr>:
+	Check dependency rules against packages in expr (default "./...").
+	Exits with a non-zero status when any rule is violated.
+
+	The rules file contains one rule per line, "#" starts a comment:
+
+		# domain must not depend on infra, directly or indirectly
+		deny: ./domain/... -> ./infra/...
+		deny: reach(./app/..., ./infra/...:all)
+
+		# every package in the result is a violation
+		deny: cgo(./...:all)
+
+		# only ./cmd/... may directly import cobra
+		allow-only: ./cmd/... -> github.com/spf13/cobra
+
+	With -baseline, violations listed in the baseline file are ignored
+	and baseline entries that no longer occur are reported as fixed.
+	Use -write-baseline to record the current violations.
+
+	With -format sarif, violations are written as SARIF 2.1.0, where
+	each result points to the import spec causing the violation.
+	Rules can be named to keep their SARIF rule id stable, when the
+	rule text or order changes, otherwise the n-th rule is "GODA<n>":
+
+		deny no-infra: ./domain/... -> ./infra/...
+
+	Additional rule kinds:
+
+		# any package from the modules is a violation
+		deny-module: github.com/pkg/errors
+
+		# packages that disable dead code elimination
+		deny: deadcode(./...:all)
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.rules, "rules", "goda.rules", "file containing the rules")
+	f.StringVar(&cmd.baseline, "baseline", "", "file containing known violations")
+	f.StringVar(&cmd.format, "format", "text", "output format (text, sarif)")
+	f.BoolVar(&cmd.writeBaseline, "write-baseline", false, "write current violations to -baseline file")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	rulesFile, err := os.Open(cmd.rules)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	rules, err := ParseRules(cmd.rules, rulesFile)
+	_ = rulesFile.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	scope := f.Args()
+	if len(scope) == 0 {
+		scope = []string{"./..."}
+	}
+
+	if cmd.format != "text" && cmd.format != "sarif" {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", cmd.format)
+		return subcommands.ExitUsageError
+	}
+
+	checker, err := NewChecker(ctx, scope)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	var violations []Violation
+	for _, rule := range rules {
+		ruleViolations, err := checker.Check(rule)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", rule.Pos(), err)
+			return subcommands.ExitFailure
+		}
+		violations = append(violations, ruleViolations...)
+	}
+
+	if cmd.writeBaseline {
+		if cmd.baseline == "" {
+			fmt.Fprintln(os.Stderr, "-write-baseline requires -baseline")
+			return subcommands.ExitUsageError
+		}
+		if err := WriteBaseline(cmd.baseline, violations); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		fmt.Fprintf(os.Stdout, "wrote %d violations to %s\n", len(violations), cmd.baseline)
+		return subcommands.ExitSuccess
+	}
+
+	var fixed []BaselineEntry
+	if cmd.baseline != "" {
+		baseline, err := ReadBaseline(cmd.baseline)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		violations, fixed = baseline.Filter(filepath.Dir(cmd.baseline), violations)
+	}
+
+	status := subcommands.ExitSuccess
+	if len(violations) > 0 {
+		status = subcommands.ExitFailure
+	}
+
+	if cmd.format == "sarif" {
+		for _, entry := range fixed {
+			fmt.Fprintf(os.Stderr, "fixed baseline entry: %s -> %s %s\n", entry.Importer, entry.Imported, entry.File)
+		}
+		if err := WriteSARIF(os.Stdout, ".", rules, violations); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		return status
+	}
+
+	var lastRule *Rule
+	for _, violation := range violations {
+		if violation.Rule != lastRule {
+			lastRule = violation.Rule
+			fmt.Fprintf(os.Stdout, "%s: %s\n", lastRule.Pos(), lastRule)
+		}
+		printViolation(violation)
+	}
+
+	if len(fixed) > 0 {
+		fmt.Fprintf(os.Stdout, "fixed %d baseline entries, update %s:\n", len(fixed), cmd.baseline)
+		for _, entry := range fixed {
+			if entry.Importer == "" {
+				fmt.Fprintf(os.Stdout, "\t%s\n", entry.Imported)
+			} else {
+				fmt.Fprintf(os.Stdout, "\t%s -> %s\t%s\n", entry.Importer, entry.Imported, entry.File)
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		fmt.Fprintf(os.Stdout, "%d violations\n", len(violations))
+	}
+	return status
+}
+
+func printViolation(violation Violation) {
+	if violation.Importer == nil {
+		fmt.Fprintf(os.Stdout, "\t%s\n", violation.Imported.ID)
+	} else {
+		fmt.Fprintf(os.Stdout, "\t%s -> %s\n", violation.Importer.ID, violation.Imported.ID)
+	}
+	if len(violation.Chain) > 2 || violation.Importer == nil && len(violation.Chain) > 1 {
+		fmt.Fprintf(os.Stdout, "\t\tchain: %s\n", violation.ChainString())
+	}
+	for _, site := range violation.Sites {
+		fmt.Fprintf(os.Stdout, "\t\tat %s\n", site.Position())
+	}
+}
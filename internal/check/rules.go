@@ -0,0 +1,164 @@
+package check
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/pkgset/ast"
+)
+
+const (
+	// Deny rules forbid packages from X importing packages in Y,
+	// directly or indirectly, e.g. "deny: ./domain/... -> ./infra/...".
+	//
+	// Alternatively, the rule can be an expression, where every package
+	// in the result is a violation, e.g. "deny: cgo(./...:all)".
+	Deny = "deny"
+	// AllowOnly rules allow only packages from X to directly import
+	// packages in Y, e.g. "allow-only: ./cmd/... -> github.com/spf13/cobra".
+	AllowOnly = "allow-only"
+	// DenyModule rules forbid depending on any package from the listed
+	// modules, e.g. "deny-module: github.com/pkg/errors".
+	DenyModule = "deny-module"
+)
+
+// Rule is a single dependency rule.
+type Rule struct {
+	File string
+	Line int
+	Kind string
+	Text string
+
+	// ID identifies the rule across runs, it's the name of the rule,
+	// e.g. "no-infra" in "deny no-infra: X -> Y", or "GODA<n>" for
+	// the n-th rule in the file.
+	ID string
+
+	// From and To are set for rules in form "X -> Y" or "reach(X, Y)".
+	From ast.Expr
+	To   ast.Expr
+	// Expr is set for deny rules with a single expression.
+	Expr ast.Expr
+	// Modules is set for deny-module rules.
+	Modules []string
+}
+
+// Pos returns the location of the rule.
+func (rule *Rule) Pos() string { return fmt.Sprintf("%s:%d", rule.File, rule.Line) }
+
+func (rule *Rule) String() string { return rule.Kind + ": " + rule.Text }
+
+// ParseRules parses rules, one per line, where "#" starts a comment.
+func ParseRules(filename string, r io.Reader) ([]*Rule, error) {
+	var rules []*Rule
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		head, text, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected \"kind: rule\"", filename, lineNumber)
+		}
+		kind, name, err := parseRuleHead(head)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filename, lineNumber, err)
+		}
+		rule := &Rule{
+			File: filename,
+			Line: lineNumber,
+			Kind: kind,
+			Text: strings.TrimSpace(text),
+			ID:   name,
+		}
+		if rule.ID == "" {
+			rule.ID = fmt.Sprintf("GODA%d", len(rules)+1)
+		}
+
+		if rule.Kind == DenyModule {
+			rule.Modules = strings.Fields(rule.Text)
+			if len(rule.Modules) == 0 {
+				return nil, fmt.Errorf("%s:%d: deny-module requires module paths", filename, lineNumber)
+			}
+			rules = append(rules, rule)
+			continue
+		}
+
+		parse := func(text string) (ast.Expr, error) {
+			expr, err := pkgset.Parse(context.Background(), []string{text})
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", filename, lineNumber, err)
+			}
+			if expr == nil {
+				return nil, fmt.Errorf("%s:%d: empty expression", filename, lineNumber)
+			}
+			return expr, nil
+		}
+
+		if from, to, ok := strings.Cut(rule.Text, "->"); ok {
+			if rule.From, err = parse(from); err != nil {
+				return nil, err
+			}
+			if rule.To, err = parse(to); err != nil {
+				return nil, err
+			}
+		} else {
+			expr, err := parse(rule.Text)
+			if err != nil {
+				return nil, err
+			}
+			// reach(X, Y) is equivalent to X -> Y.
+			if fn, ok := expr.(ast.Func); ok && strings.EqualFold(fn.Name, "reach") && len(fn.Args) == 2 {
+				rule.From, rule.To = fn.Args[0], fn.Args[1]
+			} else {
+				rule.Expr = expr
+			}
+		}
+
+		switch rule.Kind {
+		case Deny:
+		case AllowOnly:
+			if rule.Expr != nil {
+				return nil, fmt.Errorf("%s:%d: allow-only requires \"X -> Y\"", filename, lineNumber)
+			}
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown rule kind %q", filename, lineNumber, rule.Kind)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// parseRuleHead parses "kind" or "kind name".
+func parseRuleHead(head string) (kind, name string, err error) {
+	fields := strings.Fields(head)
+	switch len(fields) {
+	case 1:
+		return strings.ToLower(fields[0]), "", nil
+	case 2:
+		if strings.ContainsFunc(fields[1], func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.'
+		}) {
+			return "", "", fmt.Errorf("invalid rule name %q", fields[1])
+		}
+		return strings.ToLower(fields[0]), fields[1], nil
+	default:
+		return "", "", fmt.Errorf("expected \"kind: rule\" or \"kind name: rule\"")
+	}
+}
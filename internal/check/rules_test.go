@@ -0,0 +1,88 @@
+package check
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/pkgset/ast"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("goda.rules", strings.NewReader(`
+# layering
+deny: ./domain/... -> ./infra/...
+deny: reach(./app/..., ./infra/...:all)  # transitive
+deny: cgo(./...:all)
+allow-only: ./cmd/... -> github.com/spf13/cobra
+deny-module: github.com/pkg/errors golang.org/x/exp
+deny no-net: ./... -> net
+deny: reach(./... - ./internal/check, flag)
+deny: reach(./internal/graph ./internal/cut, flag:all)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type expect struct {
+		line           int
+		id             string
+		kind, from, to string
+		expr           string
+		modules        string
+	}
+	expected := []expect{
+		{line: 3, id: "GODA1", kind: Deny, from: "./domain/...", to: "./infra/..."},
+		{line: 4, id: "GODA2", kind: Deny, from: "./app/...", to: "./infra/...:all"},
+		{line: 5, id: "GODA3", kind: Deny, expr: "cgo(./...:all)"},
+		{line: 6, id: "GODA4", kind: AllowOnly, from: "./cmd/...", to: "github.com/spf13/cobra"},
+		{line: 7, id: "GODA5", kind: DenyModule, modules: "github.com/pkg/errors golang.org/x/exp"},
+		{line: 8, id: "no-net", kind: Deny, from: "./...", to: "net"},
+		{line: 9, id: "GODA7", kind: Deny, from: "./... - ./internal/check", to: "flag"},
+		{line: 10, id: "GODA8", kind: Deny, from: "./internal/graph ./internal/cut", to: "flag:all"},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %d", len(expected), len(rules))
+	}
+	for i, rule := range rules {
+		got := expect{line: rule.Line, id: rule.ID, kind: rule.Kind, from: tree(rule.From), to: tree(rule.To), expr: tree(rule.Expr), modules: strings.Join(rule.Modules, " ")}
+		exp := expected[i]
+		exp.from, exp.to, exp.expr = treeOf(t, exp.from), treeOf(t, exp.to), treeOf(t, exp.expr)
+		if got != exp {
+			t.Errorf("rule %d: expected %+v, got %+v", i, expected[i], got)
+		}
+	}
+
+	for _, invalid := range []string{
+		"deny ./a -> ./b",
+		"allow-only: ./a",
+		"forbid: ./a -> ./b",
+		"deny-module: ",
+		"deny a b: ./a -> ./b",
+		"deny no/slash: ./a -> ./b",
+	} {
+		if _, err := ParseRules("goda.rules", strings.NewReader(invalid)); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func tree(expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	return expr.Tree(0)
+}
+
+func treeOf(t *testing.T, text string) string {
+	t.Helper()
+	if text == "" {
+		return ""
+	}
+	expr, err := pkgset.Parse(context.Background(), []string{text})
+	if err != nil {
+		t.Fatalf("%q: %v", text, err)
+	}
+	return tree(expr)
+}
@@ -0,0 +1,220 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/stat"
+)
+
+// SARIF 2.1.0 structures, only the parts used by goda.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool               sarifTool                        `json:"tool"`
+		OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+		Results            []sarifResult                    `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+
+	sarifLogicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+		Kind               string `json:"kind"`
+	}
+)
+
+// WriteSARIF writes violations as a SARIF 2.1.0 log, with one result
+// for each import site. Paths are relative to dir, files outside of dir
+// are only described by the logical location of the package.
+func WriteSARIF(w io.Writer, dir string, rules []*Rule, violations []Violation) error {
+	absdir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "goda",
+			InformationURI: "https://github.com/loov/goda",
+			Rules:          []sarifRule{},
+		}},
+		OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+			"%SRCROOT%": {URI: "file://" + filepath.ToSlash(absdir) + "/"},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndex := map[*Rule]int{}
+	for i, rule := range rules {
+		ruleIndex[rule] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               rule.ID,
+			ShortDescription: sarifMessage{Text: rule.String()},
+		})
+	}
+
+	for _, violation := range violations {
+		result := sarifResult{
+			RuleID:    violation.Rule.ID,
+			RuleIndex: ruleIndex[violation.Rule],
+			Level:     "error",
+			Message:   sarifMessage{Text: violation.Message()},
+		}
+		logical := []sarifLogicalLocation{{
+			FullyQualifiedName: violation.Imported.ID,
+			Kind:               "package",
+		}}
+
+		// Sites outside of the source root, e.g. in the module cache,
+		// can't be shown by code scanning.
+		var sites []stat.ImportSite
+		for _, site := range violation.Sites {
+			if _, ok := sourcePath(absdir, site.File); ok {
+				sites = append(sites, site)
+			}
+		}
+
+		if len(sites) == 0 {
+			location := sarifLocation{LogicalLocations: logical}
+			if file, ok := violation.sourceFile(absdir); ok {
+				location.PhysicalLocation = &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{
+						URI:       file,
+						URIBaseID: "%SRCROOT%",
+					},
+				}
+			}
+			result.Locations = []sarifLocation{location}
+			run.Results = append(run.Results, result)
+			continue
+		}
+
+		for _, site := range sites {
+			uri, _ := sourcePath(absdir, site.File)
+			result.Locations = []sarifLocation{{
+				PhysicalLocation: &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{
+						URI:       uri,
+						URIBaseID: "%SRCROOT%",
+					},
+					Region: &sarifRegion{
+						StartLine:   site.Line,
+						StartColumn: site.Column,
+					},
+				},
+				LogicalLocations: logical,
+			}}
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sourceFile returns a file under absdir, which is related to the violation,
+// preferring the importer, then the imported package and then the chain.
+// Files outside of absdir, e.g. in the module cache, are not used.
+func (violation Violation) sourceFile(absdir string) (string, bool) {
+	candidates := []*packages.Package{violation.Importer, violation.Imported}
+	for i := len(violation.Chain) - 1; i >= 0; i-- {
+		candidates = append(candidates, violation.Chain[i])
+	}
+	for _, p := range candidates {
+		if p == nil {
+			continue
+		}
+		for _, file := range p.GoFiles {
+			if rel, ok := sourcePath(absdir, file); ok {
+				return rel, true
+			}
+		}
+	}
+	return "", false
+}
+
+// sourcePath returns file relative to absdir, when it's inside absdir.
+func sourcePath(absdir, file string) (string, bool) {
+	rel, err := filepath.Rel(absdir, file)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// Message describes the violation together with the import chain.
+func (violation Violation) Message() string {
+	var message string
+	if violation.Importer == nil {
+		message = fmt.Sprintf("%s is denied by %q", violation.Imported.ID, violation.Rule.String())
+	} else if violation.Direct() {
+		message = fmt.Sprintf("%s imports %s, denied by %q", violation.Importer.ID, violation.Imported.ID, violation.Rule.String())
+	} else {
+		message = fmt.Sprintf("%s depends on %s, denied by %q", violation.Importer.ID, violation.Imported.ID, violation.Rule.String())
+	}
+	if len(violation.Chain) > 1 {
+		message += "\nimport chain: " + violation.ChainString()
+	}
+	return message
+}
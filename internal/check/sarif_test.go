@@ -0,0 +1,88 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkgset/ast"
+	"github.com/loov/goda/internal/stat"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	a := &packages.Package{ID: "example.com/a"}
+	b := &packages.Package{ID: "example.com/b"}
+	rule := &Rule{File: "goda.rules", Line: 1, Kind: Deny, Text: "./a -> ./b", ID: "no-b", From: ast.Package("./a"), To: ast.Package("./b")}
+
+	var buf bytes.Buffer
+	err := WriteSARIF(&buf, "/src", []*Rule{rule}, []Violation{{
+		Rule:     rule,
+		Importer: a,
+		Imported: b,
+		Chain:    []*packages.Package{a, b},
+		Sites:    []stat.ImportSite{{Path: b.ID, File: "/src/a/a.go", Line: 3, Column: 2}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("invalid log %+v", log)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	location := results[0].Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "a/a.go" || location.Region.StartLine != 3 || location.Region.StartColumn != 2 {
+		t.Errorf("invalid location %+v %+v", location.ArtifactLocation, location.Region)
+	}
+	driverRule := log.Runs[0].Tool.Driver.Rules[0]
+	if results[0].RuleID != "no-b" || driverRule.ID != "no-b" || driverRule.ShortDescription.Text != rule.String() {
+		t.Errorf("unexpected rule %+v for result rule id %q", driverRule, results[0].RuleID)
+	}
+	if kind := results[0].Locations[0].LogicalLocations[0].Kind; kind != "package" {
+		t.Errorf("expected package logical location, got %q", kind)
+	}
+}
+
+func TestWriteSARIFWithoutSites(t *testing.T) {
+	app := &packages.Package{ID: "example.com/app", GoFiles: []string{"/src/app/main.go"}}
+	dep := &packages.Package{ID: "example.com/dep", GoFiles: []string{"/home/go/pkg/mod/example.com/dep@v1.0.0/dep.go"}}
+	rule := &Rule{File: "goda.rules", Line: 1, Kind: Deny, Text: "cgo(./...:all)", ID: "no-cgo", Expr: ast.Package("./...")}
+
+	locations := func(violation Violation) []sarifLocation {
+		var buf bytes.Buffer
+		if err := WriteSARIF(&buf, "/src", []*Rule{rule}, []Violation{violation}); err != nil {
+			t.Fatal(err)
+		}
+		var log sarifLog
+		if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+			t.Fatal(err)
+		}
+		return log.Runs[0].Results[0].Locations
+	}
+
+	// The file of the imported package is outside of the source root.
+	got := locations(Violation{Rule: rule, Imported: dep, Chain: []*packages.Package{app, dep}})
+	if physical := got[0].PhysicalLocation; physical == nil || physical.ArtifactLocation.URI != "app/main.go" {
+		t.Errorf("expected location in app/main.go, got %+v", physical)
+	}
+	if logical := got[0].LogicalLocations; len(logical) != 1 || logical[0].FullyQualifiedName != dep.ID {
+		t.Errorf("unexpected logical locations %+v", logical)
+	}
+
+	// Import sites outside of the source root are ignored as well.
+	got = locations(Violation{Rule: rule, Importer: dep, Imported: dep, Chain: []*packages.Package{dep},
+		Sites: []stat.ImportSite{{Path: "C", File: "/home/go/pkg/mod/example.com/dep@v1.0.0/dep.go", Line: 3}},
+	})
+	if physical := got[0].PhysicalLocation; physical != nil {
+		t.Errorf("expected only a logical location, got %+v", physical.ArtifactLocation)
+	}
+}
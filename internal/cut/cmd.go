@@ -15,15 +15,21 @@ import (
 	"github.com/google/subcommands"
 	"golang.org/x/tools/go/packages"
 
+	"github.com/loov/goda/internal/memory"
 	"github.com/loov/goda/internal/pkggraph"
 	"github.com/loov/goda/internal/pkgset"
 	"github.com/loov/goda/internal/stat"
 	"github.com/loov/goda/internal/templates"
+	"github.com/loov/goda/internal/weight/nm"
 )
 
 type Command struct {
 	printStandard bool
 	exclude       string
+	remove        string
+	suggest       int
+	binary        string
+	holders       bool
 
 	noAlign bool
 	header  string
@@ -37,6 +43,22 @@ func (*Command) Usage() string {
 	Print information about indirect-dependencies.
 	It shows packages whose removal would remove the most indirect dependencies.
 
+	With -remove the packages matching the expression are removed
+	together and the combined cut is printed, including dependencies,
+	which remain reachable via other packages.
+
+	With -binary the symbol sizes from a built binary are attributed
+	to packages and CutBinary shows the bytes that would be removed
+	from the binary. Symbols of the main package are only attributed,
+	when the expression contains a single main package.
+
+	With -holders, for each package, the importers in the main module
+	and the minimal set of imports to remove to cut the package
+	are printed.
+
+	With -suggest N, N packages are picked greedily, such that
+	removing them together removes the most packages.
+
 	See "help expr" for further information about expressions.
 	See "help format" for further information about formatting.
 `
@@ -45,13 +67,23 @@ func (*Command) Usage() string {
 func (cmd *Command) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
 	f.StringVar(&cmd.exclude, "exclude", "", "package expr to exclude from output")
+	f.StringVar(&cmd.remove, "remove", "", "package expr to remove together")
+	f.IntVar(&cmd.suggest, "suggest", 0, "suggest `N` packages to remove together")
+	f.StringVar(&cmd.binary, "binary", "", "attribute symbol sizes from a built `binary`")
+	f.BoolVar(&cmd.holders, "holders", false, "print importers and imports to remove for each package")
 
 	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
 	f.StringVar(&cmd.header, "h", "", "header for the table\nautomatically derives from format, when empty, use \"-\" to skip")
-	f.StringVar(&cmd.format, "f", "{{.ID}}\t{{.InDegree}}\t{{.Cut.PackageCount}}\t{{.Cut.AllFiles.Size}}\t{{.Cut.Go.Lines}}", "info formatting")
+	f.StringVar(&cmd.format, "f", defaultFormat, "info formatting")
 }
 
+const defaultFormat = "{{.ID}}\t{{.InDegree}}\t{{.Cut.PackageCount}}\t{{.Cut.AllFiles.Size}}\t{{.Cut.Go.Lines}}"
+
 func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if cmd.binary != "" && cmd.format == defaultFormat {
+		cmd.format += "\t{{.CutBinary}}"
+	}
+
 	t, err := templates.Parse(cmd.format)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "invalid label string: %v\n", err)
@@ -117,9 +149,65 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		}
 	}
 
+	if cmd.binary != "" {
+		syms, err := nm.ParseBinary(cmd.binary)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "loading syms failed: %v\n", err)
+			return subcommands.ExitFailure
+		}
+
+		sizes := map[string]memory.Bytes{}
+		for _, sym := range syms {
+			if sym.Code.ConsumesBinary() {
+				sizes[sym.Package()] += memory.Bytes(sym.Size)
+			}
+		}
+		// The linker names symbols of the main package "main".
+		var mains []*Node
+		for _, node := range nodes {
+			node.Binary = sizes[node.PkgPath]
+			if node.Name == "main" {
+				mains = append(mains, node)
+			}
+		}
+		if len(mains) == 1 {
+			mains[0].Binary = sizes["main"]
+		}
+	}
+	hasBinary := cmd.binary != ""
+
+	if cmd.remove != "" {
+		removed, err := pkgset.Calc(ctx, strings.Fields(cmd.remove))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		var remove []*Node
+		for _, node := range nodelist {
+			if _, ok := removed[node.ID]; ok {
+				remove = append(remove, node)
+			}
+		}
+		printRemove(os.Stdout, nodes, remove, hasBinary)
+		return subcommands.ExitSuccess
+	}
+
+	if cmd.suggest > 0 {
+		var candidates []*Node
+		for _, node := range nodelist {
+			if _, exclude := excluded[node.ID]; !exclude && node.InDegree() > 0 {
+				candidates = append(candidates, node)
+			}
+		}
+		printSuggest(os.Stdout, nodes, candidates, cmd.suggest, hasBinary)
+		return subcommands.ExitSuccess
+	}
+
 	for _, node := range nodelist {
 		Reset(nodes)
-		node.Cut = Erase(node)
+		var erased map[*Node]bool
+		node.Cut, erased = EraseAll([]*Node{node})
+		node.CutBinary = BinarySize(erased)
 	}
 
 	sort.Slice(nodelist, func(i, k int) bool {
@@ -129,6 +217,23 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		return nodelist[i].InDegree() < nodelist[k].InDegree()
 	})
 
+	if cmd.holders {
+		var roots []*Node
+		for _, node := range nodelist {
+			if node.InDegree() == 0 {
+				roots = append(roots, node)
+			}
+		}
+		for _, node := range nodelist {
+			if _, exclude := excluded[node.ID]; exclude || node.InDegree() == 0 {
+				continue
+			}
+			node.Holders = Holders(roots, node)
+			printHolders(node, hasBinary)
+		}
+		return subcommands.ExitSuccess
+	}
+
 	var w io.Writer = os.Stdout
 	if !cmd.noAlign {
 		w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
@@ -164,21 +269,180 @@ func Reset(stats map[string]*Node) {
 	}
 }
 
-func Erase(stat *Node) stat.Stat {
-	cut := stat.Stat
-	for _, imp := range stat.Imports {
-		imp.indegree--
-		if imp.indegree == 0 {
-			cut.Add(Erase(imp))
+// EraseAll removes all of set together and returns the stat of the
+// removed packages and packages that are only imported via set.
+func EraseAll(set []*Node) (cut stat.Stat, erased map[*Node]bool) {
+	erased = map[*Node]bool{}
+	var erase func(n *Node)
+	erase = func(n *Node) {
+		if erased[n] {
+			return
+		}
+		erased[n] = true
+		cut.Add(n.Stat)
+		for _, imp := range n.Imports {
+			imp.indegree--
+			if imp.indegree == 0 {
+				erase(imp)
+			}
+		}
+	}
+	for _, n := range set {
+		erase(n)
+	}
+	return cut, erased
+}
+
+// BinarySize returns the binary size of symbols in the erased packages.
+func BinarySize(erased map[*Node]bool) memory.Bytes {
+	var size memory.Bytes
+	for n := range erased {
+		size += n.Binary
+	}
+	return size
+}
+
+func printRemove(out io.Writer, nodes map[string]*Node, remove []*Node, hasBinary bool) {
+	Reset(nodes)
+	cut, erased := EraseAll(remove)
+
+	fmt.Fprintf(out, "removing %d packages removes %d packages, %v, %d lines of Go",
+		len(remove), cut.PackageCount, cut.AllFiles().Size, cut.Go.Lines)
+	if hasBinary {
+		fmt.Fprintf(out, ", %v of binary", BinarySize(erased))
+	}
+	fmt.Fprintln(out)
+
+	// Find dependencies of the removed packages that remain.
+	var list []*Node
+	visited := map[*Node]bool{}
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		for _, imp := range n.Imports {
+			if visited[imp] {
+				continue
+			}
+			visited[imp] = true
+			if !erased[imp] {
+				list = append(list, imp)
+			}
+			visit(imp)
+		}
+	}
+	for _, n := range remove {
+		visit(n)
+	}
+	if len(list) == 0 {
+		return
+	}
+	sort.Slice(list, func(i, k int) bool { return list[i].ID < list[k].ID })
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "still reachable via other packages:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, n := range list {
+		var importers []string
+		for _, importer := range n.ImportedBy {
+			if !erased[importer] {
+				importers = append(importers, importer.ID)
+			}
+		}
+		sort.Strings(importers)
+		fmt.Fprintf(w, "  %v\timported by %v\n", n.ID, strings.Join(importers, " "))
+	}
+	_ = w.Flush()
+}
+
+func printHolders(node *Node, hasBinary bool) {
+	fmt.Fprintf(os.Stdout, "%v: cuts %d packages, %v", node.ID, node.Cut.PackageCount, node.Cut.AllFiles().Size)
+	if hasBinary {
+		fmt.Fprintf(os.Stdout, ", %v of binary", node.CutBinary)
+	}
+	fmt.Fprintln(os.Stdout)
+
+	if importers := node.MainImporters(); len(importers) > 0 {
+		fmt.Fprintln(os.Stdout, "    imported in main module by:")
+		for _, importer := range importers {
+			fmt.Fprintf(os.Stdout, "        %v\n", importer.ID)
+		}
+	}
+	fmt.Fprintln(os.Stdout, "    remove imports:")
+	for _, edge := range node.Holders {
+		fmt.Fprintf(os.Stdout, "        %v\n", edge)
+	}
+}
+
+// printSuggest greedily picks packages, which remove the most packages
+// or binary size together.
+func printSuggest(out io.Writer, nodes map[string]*Node, candidates []*Node, count int, hasBinary bool) {
+	var chosen []*Node
+	var total stat.Stat
+	var totalBinary memory.Bytes
+
+	better := func(cut stat.Stat, binary memory.Bytes, bestCut stat.Stat, bestBinary memory.Bytes) bool {
+		if hasBinary && binary != bestBinary {
+			return binary > bestBinary
+		}
+		if cut.PackageCount != bestCut.PackageCount {
+			return cut.PackageCount > bestCut.PackageCount
+		}
+		return cut.AllFiles().Size > bestCut.AllFiles().Size
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer func() { _ = w.Flush() }()
+	if hasBinary {
+		fmt.Fprintln(w, "ID\t+Packages\t+Size\t+Binary\tPackages\tSize\tBinary")
+	} else {
+		fmt.Fprintln(w, "ID\t+Packages\t+Size\tPackages\tSize")
+	}
+
+	for range count {
+		var best *Node
+		var bestCut stat.Stat
+		var bestBinary memory.Bytes
+		for _, candidate := range candidates {
+			if slices.Contains(chosen, candidate) {
+				continue
+			}
+			Reset(nodes)
+			cut, erased := EraseAll(append(slices.Clone(chosen), candidate))
+			binary := BinarySize(erased)
+			if best == nil || better(cut, binary, bestCut, bestBinary) {
+				best, bestCut, bestBinary = candidate, cut, binary
+			}
+		}
+		if best == nil || !better(bestCut, bestBinary, total, totalBinary) {
+			break
+		}
+
+		chosen = append(chosen, best)
+		gain := bestCut
+		gain.Sub(total)
+		gainBinary := bestBinary - totalBinary
+		total, totalBinary = bestCut, bestBinary
+		if hasBinary {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", best.ID,
+				gain.PackageCount, gain.AllFiles().Size, gainBinary,
+				total.PackageCount, total.AllFiles().Size, totalBinary)
+		} else {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", best.ID,
+				gain.PackageCount, gain.AllFiles().Size,
+				total.PackageCount, total.AllFiles().Size)
 		}
 	}
-	return cut
 }
 
 type Node struct {
 	*pkggraph.Node
 
 	Cut stat.Stat
+	// Binary is the size of symbols of this package in the binary.
+	Binary memory.Bytes
+	// CutBinary is the binary size that would be removed with this package.
+	CutBinary memory.Bytes
+	// Holders are the imports to remove to cut this package, set with -holders.
+	Holders []Edge
 
 	Imports    []*Node
 	ImportedBy []*Node
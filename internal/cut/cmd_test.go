@@ -0,0 +1,105 @@
+package cut
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/memory"
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/stat"
+)
+
+// testGraph creates nodes, where every package has the given size:
+//
+//	main -> x, y, z
+//	x    -> shared, xonly
+//	y    -> shared
+//	z    -> shared
+func testGraph() map[string]*Node {
+	nodes := map[string]*Node{}
+	for id, size := range map[string]memory.Bytes{
+		"main": 1, "x": 1, "xonly": 1, "y": 10, "z": 20, "shared": 5,
+	} {
+		n := &Node{Node: &pkggraph.Node{Package: &packages.Package{ID: id}}}
+		n.Stat = stat.Stat{PackageCount: 1, Go: stat.Source{Files: 1, Size: size}}
+		n.Binary = 100 * size
+		nodes[id] = n
+	}
+	imports := func(from string, to ...string) {
+		for _, id := range to {
+			nodes[from].Import(nodes[id])
+		}
+	}
+	imports("main", "x", "y", "z")
+	imports("x", "shared", "xonly")
+	imports("y", "shared")
+	imports("z", "shared")
+	return nodes
+}
+
+func TestEraseAll(t *testing.T) {
+	nodes := testGraph()
+
+	Reset(nodes)
+	cut, erased := EraseAll([]*Node{nodes["x"], nodes["y"]})
+	if cut.PackageCount != 3 || cut.Go.Size != 12 {
+		t.Errorf("got %d packages, %v, expected 3 packages, 12B", cut.PackageCount, cut.Go.Size)
+	}
+	for _, id := range []string{"x", "y", "xonly"} {
+		if !erased[nodes[id]] {
+			t.Errorf("expected %v to be erased", id)
+		}
+	}
+	if erased[nodes["shared"]] {
+		t.Errorf("shared is still imported by z")
+	}
+	if got := BinarySize(erased); got != 1200 {
+		t.Errorf("got binary size %v, expected 1200", got)
+	}
+
+	Reset(nodes)
+	cut, _ = EraseAll([]*Node{nodes["x"], nodes["y"], nodes["z"]})
+	if cut.PackageCount != 5 {
+		t.Errorf("removing x, y and z: got %d packages, expected 5", cut.PackageCount)
+	}
+}
+
+func TestPrintRemove(t *testing.T) {
+	nodes := testGraph()
+
+	var out bytes.Buffer
+	printRemove(&out, nodes, []*Node{nodes["x"], nodes["y"]}, true)
+
+	expected := "removing 2 packages removes 3 packages, 12B, 0 lines of Go, 1.2KB of binary\n" +
+		"\n" +
+		"still reachable via other packages:\n" +
+		"  shared  imported by z\n"
+	if got := out.String(); got != expected {
+		t.Errorf("got:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestPrintSuggest(t *testing.T) {
+	nodes := testGraph()
+	var candidates []*Node
+	for _, id := range []string{"shared", "x", "xonly", "y", "z"} {
+		candidates = append(candidates, nodes[id])
+	}
+
+	var out bytes.Buffer
+	printSuggest(&out, nodes, candidates, 10, false)
+
+	// x removes most packages, then z and y tie on packages, but z is
+	// larger, and then nothing more can be removed.
+	var picked []string
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	for _, line := range lines[1:] {
+		picked = append(picked, strings.Fields(line)[0]+" "+strings.Fields(line)[3])
+	}
+	if got, expected := strings.Join(picked, ", "), "x 2, z 3, y 5"; got != expected {
+		t.Errorf("got %q, expected %q\n%s", got, expected, out.String())
+	}
+}
@@ -0,0 +1,125 @@
+package cut
+
+import (
+	"slices"
+	"strings"
+)
+
+// Edge is an import from From to To.
+type Edge struct {
+	From *Node
+	To   *Node
+}
+
+func (edge Edge) String() string { return edge.From.ID + " -> " + edge.To.ID }
+
+// MainImporters returns the direct importers in the main module.
+func (parent *Node) MainImporters() []*Node {
+	var importers []*Node
+	for _, importer := range parent.ImportedBy {
+		if importer.Module != nil && importer.Module.Main {
+			importers = append(importers, importer)
+		}
+	}
+	slices.SortFunc(importers, func(a, b *Node) int { return strings.Compare(a.ID, b.ID) })
+	return importers
+}
+
+// Holders finds the minimal set of import edges that need to be removed,
+// such that target is no longer reachable from roots.
+//
+// When there are multiple minimal sets, the one closest to target is
+// returned, since it removes the fewest other packages.
+func Holders(roots []*Node, target *Node) []Edge {
+	isRoot := map[*Node]bool{}
+	for _, root := range roots {
+		isRoot[root] = true
+	}
+	if isRoot[target] {
+		return nil
+	}
+
+	// Every import has capacity 1, hence the max-flow equals the
+	// number of edges in the minimal cut.
+	flow := map[Edge]bool{}
+	residual := func(from, to *Node) bool {
+		return !flow[Edge{from, to}] && hasPackage(from.Imports, to) ||
+			flow[Edge{to, from}]
+	}
+	neighbors := func(n *Node) []*Node {
+		return slices.Concat(n.Imports, n.ImportedBy)
+	}
+
+	for {
+		// Find an augmenting path from roots to target.
+		parent := map[*Node]*Node{}
+		queue := []*Node{}
+		for _, root := range roots {
+			parent[root] = nil
+			queue = append(queue, root)
+		}
+		for len(queue) > 0 && !hasKey(parent, target) {
+			n := queue[0]
+			queue = queue[1:]
+			for _, next := range neighbors(n) {
+				if hasKey(parent, next) || !residual(n, next) {
+					continue
+				}
+				parent[next] = n
+				queue = append(queue, next)
+			}
+		}
+		if !hasKey(parent, target) {
+			break
+		}
+
+		for n := target; parent[n] != nil; n = parent[n] {
+			from := parent[n]
+			if flow[Edge{n, from}] {
+				delete(flow, Edge{n, from})
+			} else {
+				flow[Edge{from, n}] = true
+			}
+		}
+	}
+
+	// Packages that can still reach target in the residual graph.
+	reaches := map[*Node]bool{target: true}
+	queue := []*Node{target}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, prev := range neighbors(n) {
+			if reaches[prev] || !residual(prev, n) {
+				continue
+			}
+			reaches[prev] = true
+			queue = append(queue, prev)
+		}
+	}
+
+	var edges []Edge
+	for n := range reaches {
+		for _, importer := range n.ImportedBy {
+			if !reaches[importer] {
+				edges = append(edges, Edge{importer, n})
+			}
+		}
+	}
+	sortEdges(edges)
+	return edges
+}
+
+func hasKey(m map[*Node]*Node, n *Node) bool {
+	_, ok := m[n]
+	return ok
+}
+
+func sortEdges(edges []Edge) {
+	slices.SortFunc(edges, func(a, b Edge) int {
+		if c := strings.Compare(a.From.ID, b.From.ID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.To.ID, b.To.ID)
+	})
+}
@@ -0,0 +1,46 @@
+package cut
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkggraph"
+)
+
+func TestHolders(t *testing.T) {
+	nodes := map[string]*Node{}
+	node := func(id string) *Node {
+		if n, ok := nodes[id]; ok {
+			return n
+		}
+		n := &Node{Node: &pkggraph.Node{Package: &packages.Package{ID: id}}}
+		nodes[id] = n
+		return n
+	}
+	imports := func(from string, to ...string) {
+		for _, id := range to {
+			node(from).Import(node(id))
+		}
+	}
+
+	// main imports the target via a and b, which are both
+	// reachable only through lib.
+	imports("main", "lib", "c")
+	imports("lib", "a", "b")
+	imports("a", "target")
+	imports("b", "target")
+	imports("c", "d")
+	imports("d", "target", "e")
+
+	got := fmt.Sprint(Holders([]*Node{node("main")}, node("target")))
+	expected := "[d -> target main -> lib]"
+	if got != expected {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+
+	if holders := Holders([]*Node{node("main")}, node("main")); holders != nil {
+		t.Errorf("root should not have holders, got %v", holders)
+	}
+}
@@ -0,0 +1,84 @@
+package cycles
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/subcommands"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkgset"
+)
+
+type Command struct {
+	level string
+}
+
+func (*Command) Name() string     { return "cycles" }
+func (*Command) Synopsis() string { return "Print import cycles between modules." }
+func (*Command) Usage() string {
+	return `cycles <expr>:
+	Print cycles between modules, which Go allows unlike package cycles,
+	and the package imports causing them.
+
+	With -level repo, modules are grouped by repository, i.e. module
+	path without the major version suffix.
+
+	Only imports between packages in expr are considered, hence
+	usually expr should include dependencies, e.g. "./...:all".
+
+	To graph the packages causing module cycles use:
+
+		goda graph "cycles(./...:all)"
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&cmd.level, "level", "module", "level of cycles (module, repo)")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	var keyOf func(*packages.Package) string
+	switch cmd.level {
+	case "module":
+		keyOf = pkgset.ModuleOf
+	case "repo":
+		keyOf = pkgset.RepoOf
+	default:
+		fmt.Fprintf(os.Stderr, "unknown level %q\n", cmd.level)
+		return subcommands.ExitUsageError
+	}
+
+	result, err := pkgset.Calc(ctx, f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	cycles := pkgset.Cycles(result, keyOf)
+	for i, cycle := range cycles {
+		if i > 0 {
+			fmt.Fprintln(os.Stdout)
+		}
+		fmt.Fprintf(os.Stdout, "%s cycle: %s\n", cmd.level, strings.Join(cycle.Members, ", "))
+
+		var from, to string
+		for _, edge := range cycle.Edges {
+			if keyOf(edge.From) != from || keyOf(edge.To) != to {
+				from, to = keyOf(edge.From), keyOf(edge.To)
+				fmt.Fprintf(os.Stdout, "  %s -> %s\n", from, to)
+			}
+			fmt.Fprintf(os.Stdout, "    %s -> %s\n", edge.From.ID, edge.To.ID)
+		}
+	}
+
+	if len(cycles) == 0 {
+		fmt.Fprintf(os.Stdout, "no %s cycles\n", cmd.level)
+	}
+	return subcommands.ExitSuccess
+}
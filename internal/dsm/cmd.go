@@ -0,0 +1,93 @@
+package dsm
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgset"
+)
+
+type Command struct {
+	printStandard bool
+	transitive    bool
+	order         string
+	outputType    string
+}
+
+func (*Command) Name() string     { return "dsm" }
+func (*Command) Synopsis() string { return "Print dependency structure matrix." }
+func (*Command) Usage() string {
+	return `dsm <expr>:
+	Print dependency structure matrix, where rows import columns
+	and cells contain the count of import specs.
+
+	With -transitive the cells contain the count of imports of the
+	column package by the row package and all of its dependencies.
+
+	Supported orders:
+
+		cluster - group by module, topologically within the module
+		topo    - dependencies before importers
+		id      - sorted by package ID
+
+	Dependencies are ordered before their importers, hence cells
+	above the diagonal are cycles or layer violations.
+
+	Supported output types: text, csv and html. The html output
+	is a self-contained page, where module blocks can be collapsed.
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.transitive, "transitive", false, "count transitive imports")
+	f.StringVar(&cmd.order, "order", "cluster", "order of packages (cluster, topo, id)")
+	f.StringVar(&cmd.outputType, "type", "text", "output type (text, csv, html)")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	var write func(m *Matrix) error
+	switch cmd.outputType {
+	case "text":
+		write = func(m *Matrix) error { return m.WriteText(os.Stdout) }
+	case "csv":
+		write = func(m *Matrix) error { return m.WriteCSV(os.Stdout) }
+	case "html":
+		write = func(m *Matrix) error { return m.WriteHTML(os.Stdout) }
+	default:
+		fmt.Fprintf(os.Stderr, "unknown output type %q\n", cmd.outputType)
+		return subcommands.ExitUsageError
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.Calc(ctx, f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	m, err := New(pkggraph.From(result), Order(cmd.order), cmd.transitive)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	if err := write(m); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,174 @@
+package dsm
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgtree"
+)
+
+// Matrix is a dependency structure matrix.
+//
+// Rows import columns, hence with dependencies ordered before their
+// importers, all the cells are below the diagonal. Cells above the
+// diagonal are cycles or layer violations.
+type Matrix struct {
+	Nodes  []*pkggraph.Node
+	Blocks []Block
+	// Cells[row][column] is the count of imports.
+	Cells [][]int
+}
+
+// Block is a range of nodes belonging to the same module.
+type Block struct {
+	Name       string
+	Start, End int
+}
+
+// Order is the order of rows and columns.
+type Order string
+
+const (
+	// OrderTopological places dependencies before their importers.
+	OrderTopological Order = "topo"
+	// OrderCluster groups packages by module and orders them
+	// topologically within the module.
+	OrderCluster Order = "cluster"
+	// OrderID sorts packages by their ID.
+	OrderID Order = "id"
+)
+
+// New creates a matrix of the graph.
+//
+// With transitive, the cell contains the count of imports of
+// the column package from the row package and its dependencies.
+func New(graph *pkggraph.Graph, order Order, transitive bool) (*Matrix, error) {
+	nodes, err := sortNodes(graph, order)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[*pkggraph.Node]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+
+	m := &Matrix{Nodes: nodes, Cells: make([][]int, len(nodes))}
+	for i, n := range nodes {
+		m.Cells[i] = make([]int, len(nodes))
+		for _, dst := range n.ImportsNodes {
+			m.Cells[i][index[dst]] = max(len(n.SitesOf(dst)), 1)
+		}
+	}
+	if transitive {
+		m.Cells = transitiveCounts(nodes, index)
+	}
+
+	for i, n := range nodes {
+		name := moduleName(n)
+		if len(m.Blocks) > 0 && m.Blocks[len(m.Blocks)-1].Name == name {
+			m.Blocks[len(m.Blocks)-1].End = i + 1
+			continue
+		}
+		m.Blocks = append(m.Blocks, Block{Name: name, Start: i, End: i + 1})
+	}
+
+	return m, nil
+}
+
+// AboveDiagonal returns the count of cells above the diagonal.
+func (m *Matrix) AboveDiagonal() int {
+	count := 0
+	for row := range m.Cells {
+		for column := row + 1; column < len(m.Cells); column++ {
+			if m.Cells[row][column] > 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// transitiveCounts counts for each row the imports of a column
+// from the row package and all of its dependencies.
+func transitiveCounts(nodes []*pkggraph.Node, index map[*pkggraph.Node]int) [][]int {
+	cells := make([][]int, len(nodes))
+	for i, n := range nodes {
+		cells[i] = make([]int, len(nodes))
+
+		visited := map[*pkggraph.Node]bool{n: true}
+		queue := []*pkggraph.Node{n}
+		for len(queue) > 0 {
+			src := queue[0]
+			queue = queue[1:]
+			for _, dst := range src.ImportsNodes {
+				cells[i][index[dst]]++
+				if !visited[dst] {
+					visited[dst] = true
+					queue = append(queue, dst)
+				}
+			}
+		}
+	}
+	return cells
+}
+
+func sortNodes(graph *pkggraph.Graph, order Order) ([]*pkggraph.Node, error) {
+	nodes := slices.Clone(graph.Sorted)
+	byLevel := func(a, b *pkggraph.Node) int {
+		return cmp.Or(cmp.Compare(a.Level(), b.Level()), cmp.Compare(a.ID, b.ID))
+	}
+
+	switch order {
+	case OrderID:
+	case OrderTopological:
+		slices.SortStableFunc(nodes, byLevel)
+	case OrderCluster:
+		tree, err := pkgtree.From(graph)
+		if err != nil {
+			return nil, err
+		}
+
+		// Modules are ordered by their deepest level,
+		// so that modules are in a topological order, when possible.
+		blockOf := map[*pkggraph.Node]int{}
+		blockLevel := map[int]int{}
+		block := 0
+		var visit func(tn pkgtree.Node)
+		visit = func(tn pkgtree.Node) {
+			switch tn := tn.(type) {
+			case *pkgtree.Module:
+				block++
+			case *pkgtree.Package:
+				blockOf[tn.GraphNode] = block
+				blockLevel[block] = max(blockLevel[block], tn.GraphNode.Level())
+			}
+			tn.VisitChildren(visit)
+		}
+		tree.VisitChildren(func(tn pkgtree.Node) {
+			block++
+			visit(tn)
+		})
+
+		slices.SortStableFunc(nodes, func(a, b *pkggraph.Node) int {
+			blockA, blockB := blockOf[a], blockOf[b]
+			return cmp.Or(
+				cmp.Compare(blockLevel[blockA], blockLevel[blockB]),
+				cmp.Compare(blockA, blockB),
+				byLevel(a, b))
+		})
+	default:
+		return nil, fmt.Errorf("unknown order %q", order)
+	}
+
+	return nodes, nil
+}
+
+func moduleName(n *pkggraph.Node) string {
+	if n.Module != nil {
+		return n.Module.Path
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package dsm
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkggraph"
+)
+
+func TestMatrix(t *testing.T) {
+	// a -> b -> c, a -> c
+	g := &pkggraph.Graph{Packages: map[string]*pkggraph.Node{}}
+	node := func(id string, imports ...*pkggraph.Node) *pkggraph.Node {
+		n := &pkggraph.Node{Package: &packages.Package{ID: id}, ImportsNodes: imports}
+		g.AddNode(n)
+		g.Sorted = append(g.Sorted, n)
+		return n
+	}
+	c := node("c")
+	b := node("b", c)
+	a := node("a", b, c)
+	pkggraph.SortNodes(g.Sorted)
+
+	m, err := New(g, OrderTopological, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Nodes[0] != c || m.Nodes[1] != b || m.Nodes[2] != a {
+		t.Fatalf("invalid topological order")
+	}
+	if m.AboveDiagonal() != 0 {
+		t.Errorf("expected no cells above diagonal, got %d", m.AboveDiagonal())
+	}
+
+	m, err = New(g, OrderID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.AboveDiagonal() != 3 {
+		t.Errorf("expected 3 cells above diagonal, got %d", m.AboveDiagonal())
+	}
+
+	m, err = New(g, OrderTopological, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a imports c directly and via b.
+	if got := m.Cells[2][0]; got != 2 {
+		t.Errorf("expected 2 transitive imports of c from a, got %d", got)
+	}
+}
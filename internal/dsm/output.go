@@ -0,0 +1,138 @@
+package dsm
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteText writes the matrix as a table with numbered columns.
+func (m *Matrix) WriteText(w io.Writer) error {
+	cellWidth := len(strconv.Itoa(len(m.Nodes)))
+	idWidth := 0
+	for row, n := range m.Nodes {
+		idWidth = max(idWidth, len(n.ID))
+		for _, count := range m.Cells[row] {
+			cellWidth = max(cellWidth, len(strconv.Itoa(count)))
+		}
+	}
+	indexWidth := len(strconv.Itoa(len(m.Nodes)))
+
+	var line strings.Builder
+	line.WriteString(strings.Repeat(" ", indexWidth+1+idWidth+1))
+	for column := range m.Nodes {
+		fmt.Fprintf(&line, " %*d", cellWidth, column+1)
+	}
+	fmt.Fprintln(w, line.String())
+
+	for _, block := range m.Blocks {
+		fmt.Fprintf(w, "# %v\n", blockName(block))
+		for row := block.Start; row < block.End; row++ {
+			line.Reset()
+			fmt.Fprintf(&line, "%*d %-*s ", indexWidth, row+1, idWidth, m.Nodes[row].ID)
+			for column, count := range m.Cells[row] {
+				switch {
+				case row == column:
+					line.WriteString(" " + strings.Repeat(" ", cellWidth-1) + "■")
+				case count == 0:
+					line.WriteString(" " + strings.Repeat(" ", cellWidth-1) + "·")
+				default:
+					fmt.Fprintf(&line, " %*d", cellWidth, count)
+				}
+			}
+			fmt.Fprintln(w, line.String())
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d dependencies above the diagonal\n", m.AboveDiagonal())
+	return nil
+}
+
+// WriteCSV writes the matrix with package ID-s as the first row and column.
+func (m *Matrix) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+
+	header := []string{""}
+	for _, n := range m.Nodes {
+		header = append(header, n.ID)
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	for row, n := range m.Nodes {
+		record := []string{n.ID}
+		for _, count := range m.Cells[row] {
+			if count == 0 {
+				record = append(record, "")
+			} else {
+				record = append(record, strconv.Itoa(count))
+			}
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+var (
+	//go:embed viewer/dsm.html
+	htmlViewerPage string
+	//go:embed viewer/dsm.js
+	htmlViewerScript string
+	//go:embed viewer/dsm.css
+	htmlViewerStyle string
+)
+
+var htmlViewer = template.Must(template.New("").Parse(htmlViewerPage))
+
+type htmlMatrix struct {
+	Nodes  []string    `json:"nodes"`
+	Blocks []htmlBlock `json:"blocks"`
+	// Cells contains [row, column, count] for non-empty cells.
+	Cells [][3]int `json:"cells"`
+}
+
+type htmlBlock struct {
+	Name  string `json:"name"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// WriteHTML writes a self-contained viewer, where module blocks can be collapsed.
+func (m *Matrix) WriteHTML(w io.Writer) error {
+	data := htmlMatrix{Cells: [][3]int{}}
+	for _, n := range m.Nodes {
+		data.Nodes = append(data.Nodes, n.ID)
+	}
+	for _, block := range m.Blocks {
+		data.Blocks = append(data.Blocks, htmlBlock{Name: blockName(block), Start: block.Start, End: block.End})
+	}
+	for row := range m.Cells {
+		for column, count := range m.Cells[row] {
+			if count > 0 {
+				data.Cells = append(data.Cells, [3]int{row, column, count})
+			}
+		}
+	}
+
+	return htmlViewer.Execute(w, map[string]any{
+		"Matrix": data,
+		"Script": template.JS(htmlViewerScript),
+		"Style":  template.CSS(htmlViewerStyle),
+	})
+}
+
+func blockName(block Block) string {
+	if block.Name == "" {
+		return "(no module)"
+	}
+	return block.Name
+}
@@ -0,0 +1,110 @@
+"use strict";
+
+// Collapsed module blocks by index.
+const collapsed = new Set();
+
+// counts[row][column] of the imports.
+const counts = goda.nodes.map(() => new Map());
+for (const [row, column, count] of goda.cells) {
+	counts[row].set(column, count);
+}
+
+// units returns rows and columns to display, where
+// a collapsed block is a single unit.
+function units() {
+	const result = [];
+	goda.blocks.forEach((block, index) => {
+		if (collapsed.has(index)) {
+			const members = [];
+			for (let i = block.start; i < block.end; i++) members.push(i);
+			result.push({ label: block.name, block: index, members });
+			return;
+		}
+		for (let i = block.start; i < block.end; i++) {
+			result.push({ label: goda.nodes[i], block: index, members: [i] });
+		}
+	});
+	return result;
+}
+
+function total(row, column) {
+	let sum = 0;
+	for (const r of row.members) {
+		for (const c of column.members) {
+			if (r !== c) sum += counts[r].get(c) || 0;
+		}
+	}
+	return sum;
+}
+
+function render() {
+	const table = document.getElementById("matrix");
+	table.textContent = "";
+	const list = units();
+
+	const header = table.insertRow();
+	header.appendChild(document.createElement("th"));
+	list.forEach((_, i) => {
+		const th = document.createElement("th");
+		th.className = "column";
+		th.textContent = i + 1;
+		header.appendChild(th);
+	});
+
+	let previousBlock = -1;
+	let above = 0;
+	list.forEach((row, r) => {
+		if (row.block !== previousBlock) {
+			previousBlock = row.block;
+			const tr = table.insertRow();
+			const th = document.createElement("th");
+			th.className = "row block";
+			th.colSpan = list.length + 1;
+			th.textContent = (collapsed.has(row.block) ? "▸ " : "▾ ") + goda.blocks[row.block].name;
+			th.onclick = ((block) => () => {
+				if (collapsed.has(block)) collapsed.delete(block);
+				else collapsed.add(block);
+				render();
+			})(row.block);
+			tr.appendChild(th);
+		}
+
+		const tr = table.insertRow();
+		const th = document.createElement("th");
+		th.className = "row";
+		th.textContent = (r + 1) + " " + row.label;
+		tr.appendChild(th);
+
+		list.forEach((column, c) => {
+			const td = tr.insertCell();
+			const count = total(row, column);
+			const classes = [];
+			if (r === c) classes.push("diagonal");
+			if (row.block === column.block) classes.push("within");
+			if (count > 0) {
+				td.textContent = count;
+				td.title = row.label + " → " + column.label;
+				if (c > r) {
+					classes.push("above");
+					above++;
+				} else if (c < r) {
+					classes.push("below");
+				}
+			}
+			td.className = classes.join(" ");
+		});
+	});
+
+	document.getElementById("summary").textContent = above + " dependencies above the diagonal";
+}
+
+document.getElementById("collapse-all").onclick = () => {
+	goda.blocks.forEach((_, i) => collapsed.add(i));
+	render();
+};
+document.getElementById("expand-all").onclick = () => {
+	collapsed.clear();
+	render();
+};
+
+render();
@@ -0,0 +1,402 @@
+package graph
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/loov/goda/internal/graph/layout"
+	"github.com/loov/goda/internal/pkggraph"
+)
+
+// ASCII renders the graph using box-drawing characters.
+type ASCII struct {
+	out io.Writer
+	err io.Writer
+
+	width   int
+	nocolor bool
+
+	label *template.Template
+}
+
+func (ctx *ASCII) Label(p *pkggraph.Node) string {
+	var labelText strings.Builder
+	err := ctx.label.Execute(&labelText, p)
+	if err != nil {
+		fmt.Fprintf(ctx.err, "template error: %v\n", err)
+	}
+	return labelText.String()
+}
+
+// asciiSegment is part of an edge between two neighbouring layers.
+type asciiSegment struct {
+	fromY    int
+	toY      int
+	track    int
+	color    string
+	reversed bool
+	toNode   bool // segment ends at a real node
+}
+
+func (ctx *ASCII) Write(graph *pkggraph.Graph) error {
+	lg, err := layoutGraph(graph, ctx.Label, false,
+		metrics{charWidth: 1, lineHeight: 1, padX: 1, padY: 1},
+		layout.Options{LayerSpacing: 1, NodeSpacing: 1})
+	if err != nil {
+		return err
+	}
+	if len(lg.Layers) == 0 || len(lg.Nodes) == 0 {
+		return nil
+	}
+
+	centerY := func(n *layout.Node) int {
+		if n.Dummy {
+			return int(n.Y)
+		}
+		return int(n.Y) + (int(n.Height)-1)/2
+	}
+
+	// Assign tracks for vertical lines between layers,
+	// segments starting from the same point share a track.
+	gaps := make([][]*asciiSegment, len(lg.Layers)-1)
+	tracks := make([]int, len(lg.Layers)-1)
+	for _, e := range lg.Edges {
+		col := ctx.edgeColorOf(lg.nodes[e.From], lg.nodes[e.To])
+		for i := 0; i+1 < len(e.Path); i++ {
+			from, to := e.Path[i], e.Path[i+1]
+			if from.Layer+1 != to.Layer {
+				continue
+			}
+			gaps[from.Layer] = append(gaps[from.Layer], &asciiSegment{
+				fromY:    centerY(from),
+				toY:      centerY(to),
+				color:    col,
+				reversed: e.Reversed && i == 0,
+				toNode:   !to.Dummy,
+			})
+		}
+	}
+	for l, segments := range gaps {
+		sort.SliceStable(segments, func(i, k int) bool { return segments[i].fromY < segments[k].fromY })
+		trackOf := map[int]int{}
+		for _, s := range segments {
+			if s.fromY == s.toY {
+				continue
+			}
+			track, ok := trackOf[s.fromY]
+			if !ok {
+				track = len(trackOf)
+				trackOf[s.fromY] = track
+			}
+			s.track = track
+		}
+		tracks[l] = len(trackOf)
+	}
+
+	// Fit labels into the available width.
+	layerLabel := make([]int, len(lg.Layers))
+	for l, layer := range lg.Layers {
+		for _, n := range layer {
+			if !n.Dummy {
+				layerLabel[l] = max(layerLabel[l], int(n.Width)-2)
+			}
+		}
+	}
+	gapWidth := func(l int) int { return tracks[l] + 3 }
+	totalWidth := func(limit int) int {
+		total := 0
+		for l := range lg.Layers {
+			total += min(layerLabel[l], limit) + 2
+			if l < len(gaps) {
+				total += gapWidth(l)
+			}
+		}
+		return total
+	}
+	limit := 0
+	for _, w := range layerLabel {
+		limit = max(limit, w)
+	}
+	for limit > 8 && totalWidth(limit) > ctx.terminalWidth() {
+		limit--
+	}
+
+	colX := make([]int, len(lg.Layers))
+	colWidth := make([]int, len(lg.Layers))
+	x := 0
+	for l := range lg.Layers {
+		colX[l] = x
+		colWidth[l] = min(layerLabel[l], limit) + 2
+		x += colWidth[l]
+		if l < len(gaps) {
+			x += gapWidth(l)
+		}
+	}
+
+	height := int(lg.Height) + 1
+	grid := newASCIIGrid(x, height)
+
+	// Draw edges.
+	for _, e := range lg.Edges {
+		col := ctx.edgeColorOf(lg.nodes[e.From], lg.nodes[e.To])
+		for _, n := range e.Path[1 : len(e.Path)-1] {
+			grid.hline(colX[n.Layer], colX[n.Layer]+colWidth[n.Layer]-1, centerY(n), col)
+		}
+	}
+	for l, segments := range gaps {
+		right := colX[l] + colWidth[l]
+		trackX := right + 1
+		for _, s := range segments {
+			startX := right
+			for _, n := range lg.Layers[l] {
+				if !n.Dummy && centerY(n) == s.fromY {
+					startX = colX[l] + min(int(n.Width), colWidth[l])
+				}
+			}
+			endX := colX[l+1] - 1
+			if s.fromY == s.toY {
+				grid.hline(startX, endX, s.fromY, s.color)
+			} else {
+				tx := trackX + s.track
+				grid.hline(startX, tx, s.fromY, s.color)
+				grid.vline(tx, s.fromY, s.toY, s.color)
+				grid.hline(tx, endX, s.toY, s.color)
+			}
+			if s.reversed {
+				grid.set(startX, s.fromY, '◀', s.color)
+			} else if s.toNode {
+				grid.set(endX, s.toY, '▶', s.color)
+			}
+		}
+	}
+
+	// Draw nodes.
+	for _, n := range lg.Nodes {
+		col := ctx.colorOf(lg.nodes[n])
+		left, top := colX[n.Layer], int(n.Y)
+		width := min(int(n.Width), colWidth[n.Layer])
+		bottom := top + int(n.Height) - 1
+		right := left + width - 1
+
+		grid.set(left, top, '┌', col)
+		grid.set(right, top, '┐', col)
+		grid.set(left, bottom, '└', col)
+		grid.set(right, bottom, '┘', col)
+		for x := left + 1; x < right; x++ {
+			grid.set(x, top, '─', col)
+			grid.set(x, bottom, '─', col)
+		}
+		for i, line := range lg.labels[n] {
+			y := top + 1 + i
+			grid.set(left, y, '│', col)
+			grid.set(right, y, '│', col)
+			for x := left + 1; x < right; x++ {
+				grid.set(x, y, ' ', "")
+			}
+			for k, r := range []rune(truncateLabel(line, width-2)) {
+				grid.set(left+1+k, y, r, "")
+			}
+		}
+	}
+
+	out := bufio.NewWriter(ctx.out)
+	grid.write(out)
+	return out.Flush()
+}
+
+// terminalWidth returns the maximum width of the output.
+func (ctx *ASCII) terminalWidth() int {
+	if ctx.width > 0 {
+		return ctx.width
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return 120
+}
+
+// truncateLabel limits label to width runes, keeping the end of the label,
+// since the last elements of an import path are the most distinctive.
+func truncateLabel(label string, width int) string {
+	count := utf8.RuneCountInString(label)
+	if count <= width {
+		return label
+	}
+	if width <= 0 {
+		return ""
+	}
+	return "…" + string([]rune(label)[count-width+1:])
+}
+
+func (ctx *ASCII) colorOf(p *pkggraph.Node) string {
+	if ctx.nocolor {
+		return ""
+	}
+
+	var c color.RGBA
+	if p.Color != "" {
+		var ok bool
+		c, ok = parseColor(p.Color)
+		if !ok {
+			return ""
+		}
+	} else {
+		hash := sha256.Sum256([]byte(p.PkgPath))
+		hue := float64(uint(hash[0])<<8|uint(hash[1])) / 0xFFFF
+		r, g, b, _ := hsla(hue, 0.8, 0.55, 1)
+		c = color.RGBA{sat8(r), sat8(g), sat8(b), 0xff}
+	}
+
+	// Use the 6x6x6 color cube from the 256 color palette.
+	cube := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	return "\x1b[38;5;" + strconv.Itoa(16+36*cube(c.R)+6*cube(c.G)+cube(c.B)) + "m"
+}
+
+func (ctx *ASCII) edgeColorOf(src, dst *pkggraph.Node) string {
+	if color := src.EdgeColor[dst]; color != "" && !ctx.nocolor {
+		return ctx.colorOf(&pkggraph.Node{Color: color})
+	}
+	return ctx.colorOf(dst)
+}
+
+const (
+	lineUp = 1 << iota
+	lineDown
+	lineLeft
+	lineRight
+)
+
+var lineRunes = map[int]rune{
+	lineLeft: '─', lineRight: '─', lineLeft | lineRight: '─',
+	lineUp: '│', lineDown: '│', lineUp | lineDown: '│',
+	lineDown | lineRight: '┌', lineDown | lineLeft: '┐',
+	lineUp | lineRight: '└', lineUp | lineLeft: '┘',
+	lineUp | lineDown | lineRight: '├', lineUp | lineDown | lineLeft: '┤',
+	lineDown | lineLeft | lineRight: '┬', lineUp | lineLeft | lineRight: '┴',
+	lineUp | lineDown | lineLeft | lineRight: '┼',
+}
+
+// asciiGrid is a character canvas, where lines are merged at junctions.
+type asciiGrid struct {
+	width, height int
+	lines         []int
+	runes         []rune
+	colors        []string
+}
+
+func newASCIIGrid(width, height int) *asciiGrid {
+	return &asciiGrid{
+		width:  width,
+		height: height,
+		lines:  make([]int, width*height),
+		runes:  make([]rune, width*height),
+		colors: make([]string, width*height),
+	}
+}
+
+func (g *asciiGrid) index(x, y int) (int, bool) {
+	if x < 0 || y < 0 || x >= g.width || y >= g.height {
+		return 0, false
+	}
+	return y*g.width + x, true
+}
+
+func (g *asciiGrid) line(x, y, dirs int, color string) {
+	if i, ok := g.index(x, y); ok {
+		g.lines[i] |= dirs
+		if g.colors[i] == "" || g.runes[i] == 0 {
+			g.colors[i] = color
+		}
+	}
+}
+
+func (g *asciiGrid) set(x, y int, r rune, color string) {
+	if i, ok := g.index(x, y); ok {
+		g.runes[i] = r
+		g.colors[i] = color
+	}
+}
+
+func (g *asciiGrid) hline(x0, x1, y int, color string) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	for x := x0; x <= x1; x++ {
+		dirs := 0
+		if x > x0 {
+			dirs |= lineLeft
+		}
+		if x < x1 {
+			dirs |= lineRight
+		}
+		if x0 == x1 {
+			dirs = lineLeft | lineRight
+		}
+		g.line(x, y, dirs, color)
+	}
+}
+
+func (g *asciiGrid) vline(x, y0, y1 int, color string) {
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	for y := y0; y <= y1; y++ {
+		dirs := 0
+		if y > y0 {
+			dirs |= lineUp
+		}
+		if y < y1 {
+			dirs |= lineDown
+		}
+		g.line(x, y, dirs, color)
+	}
+}
+
+func (g *asciiGrid) rune(x, y int) rune {
+	i := y*g.width + x
+	r := g.runes[i]
+	if r == 0 {
+		r = lineRunes[g.lines[i]]
+	}
+	if r == 0 {
+		r = ' '
+	}
+	return r
+}
+
+func (g *asciiGrid) write(w io.Writer) {
+	const reset = "\x1b[0m"
+	for y := 0; y < g.height; y++ {
+		last := g.width - 1
+		for last >= 0 && g.rune(last, y) == ' ' {
+			last--
+		}
+
+		var line strings.Builder
+		current := ""
+		for x := 0; x <= last; x++ {
+			r := g.rune(x, y)
+			if color := g.colors[y*g.width+x]; color != current && r != ' ' {
+				if current != "" {
+					line.WriteString(reset)
+				}
+				line.WriteString(color)
+				current = color
+			}
+			line.WriteRune(r)
+		}
+		if current != "" {
+			line.WriteString(reset)
+		}
+		fmt.Fprintln(w, line.String())
+	}
+}
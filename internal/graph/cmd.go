@@ -7,9 +7,11 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/google/subcommands"
 
+	"github.com/loov/goda/internal/partition"
 	"github.com/loov/goda/internal/pkggraph"
 	"github.com/loov/goda/internal/pkgset"
 	"github.com/loov/goda/internal/templates"
@@ -23,11 +25,15 @@ type Command struct {
 	outputType  string
 	labelFormat string
 
-	nocolor bool
-	colors  exprColors
+	nocolor   bool
+	colors    exprColors
+	partition int
 
 	clusters bool
 	shortID  bool
+	rank     string
+
+	width int
 }
 
 func (*Command) Name() string     { return "graph" }
@@ -50,6 +56,14 @@ Supported output types:
 
 	mermaid - mermaid flowchart
 
+	html - self-contained interactive viewer
+
+	svg - rendered graph, does not require GraphViz
+
+	png - rendered graph, does not require GraphViz
+
+	ascii - rendered graph for the terminal
+
 	See "help expr" for further information about expressions.
 	See "help format" for further information about formatting.
 `
@@ -59,15 +73,19 @@ func (cmd *Command) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
 
 	f.BoolVar(&cmd.nocolor, "nocolor", false, "disable coloring")
+	f.IntVar(&cmd.partition, "partition", 0, "color packages by proposed partition into `N` groups, see \"help partition\"")
 	f.Var(&cmd.colors, "color", "specify a color for packages in a given expr (e.g. `-color red=./...`)")
 
 	f.StringVar(&cmd.docs, "docs", "https://pkg.go.dev/", "override the docs url to use")
 
-	f.StringVar(&cmd.outputType, "type", "dot", "output type (dot, graphml, digraph, edges, tgf)")
+	f.StringVar(&cmd.outputType, "type", "dot", "output type (dot, graphml, digraph, edges, tgf, mermaid, html, svg, png, ascii)")
 	f.StringVar(&cmd.labelFormat, "f", "", "label formatting")
 
 	f.BoolVar(&cmd.clusters, "cluster", false, "create clusters")
 	f.BoolVar(&cmd.shortID, "short", false, "use short package id-s inside clusters")
+	f.StringVar(&cmd.rank, "rank", "", "align nodes with the same level or depth in dot output (level, depth)")
+
+	f.IntVar(&cmd.width, "width", 0, "maximum width of ascii output (default $COLUMNS or 120)")
 }
 
 func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
@@ -75,6 +93,8 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		switch cmd.outputType {
 		case "dot":
 			cmd.labelFormat = `{{.ID}}\l{{ .Stat.Go.Lines }} / {{ .Stat.Go.Size }}\l`
+		case "svg", "png":
+			cmd.labelFormat = `{{.ID}}\n{{ .Stat.Go.Lines }} / {{ .Stat.Go.Size }}`
 		default:
 			cmd.labelFormat = `{{.ID}}`
 		}
@@ -86,54 +106,9 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 		return subcommands.ExitFailure
 	}
 
-	var format Format
-	switch strings.ToLower(cmd.outputType) {
-	case "dot":
-		format = &Dot{
-			out:      os.Stdout,
-			err:      os.Stderr,
-			docs:     cmd.docs,
-			clusters: cmd.clusters,
-			nocolor:  cmd.nocolor,
-			shortID:  cmd.shortID,
-			label:    label,
-		}
-	case "mermaid":
-		format = &Mermaid{
-			out:     os.Stdout,
-			err:     os.Stderr,
-			docs:    cmd.docs,
-			nocolor: cmd.nocolor,
-			shortID: cmd.shortID,
-			label:   label,
-		}
-	case "digraph":
-		format = &Digraph{
-			out:   os.Stdout,
-			err:   os.Stderr,
-			label: label,
-		}
-	case "tgf":
-		format = &TGF{
-			out:   os.Stdout,
-			err:   os.Stderr,
-			label: label,
-		}
-	case "edges":
-		format = &Edges{
-			out:   os.Stdout,
-			err:   os.Stderr,
-			label: label,
-		}
-	case "graphml":
-		format = &GraphML{
-			out:     os.Stdout,
-			err:     os.Stderr,
-			label:   label,
-			nocolor: cmd.nocolor,
-		}
-	default:
-		fmt.Fprintf(os.Stderr, "unknown output type %q\n", cmd.outputType)
+	format, err := cmd.newFormat(label)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
 		return subcommands.ExitFailure
 	}
 
@@ -151,6 +126,15 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 	}
 
 	graph := pkggraph.From(result)
+	if cmd.partition > 0 {
+		p := partition.Detect(graph, cmd.partition, partition.Weights["lines"])
+		for _, g := range p.Groups {
+			color := hslhex(float64(g.ID-1)/float64(len(p.Groups)), 0.9, 0.4)
+			for _, n := range g.Nodes {
+				n.Color = color
+			}
+		}
+	}
 	for _, color := range cmd.colors {
 		target, err := pkgset.Calc(ctx, []string{color.Expr})
 		if err != nil {
@@ -172,6 +156,97 @@ func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subc
 	return subcommands.ExitSuccess
 }
 
+// newFormat creates the output format for the selected output type.
+func (cmd *Command) newFormat(label *template.Template) (Format, error) {
+	switch strings.ToLower(cmd.outputType) {
+	case "dot":
+		switch cmd.rank {
+		case "", "level", "depth":
+		default:
+			return nil, fmt.Errorf("unknown rank %q", cmd.rank)
+		}
+		return &Dot{
+			out:      os.Stdout,
+			err:      os.Stderr,
+			docs:     cmd.docs,
+			clusters: cmd.clusters,
+			nocolor:  cmd.nocolor,
+			shortID:  cmd.shortID,
+			rank:     cmd.rank,
+			label:    label,
+		}, nil
+	case "mermaid":
+		return &Mermaid{
+			out:     os.Stdout,
+			err:     os.Stderr,
+			docs:    cmd.docs,
+			nocolor: cmd.nocolor,
+			shortID: cmd.shortID,
+			label:   label,
+		}, nil
+	case "digraph":
+		return &Digraph{
+			out:   os.Stdout,
+			err:   os.Stderr,
+			label: label,
+		}, nil
+	case "tgf":
+		return &TGF{
+			out:   os.Stdout,
+			err:   os.Stderr,
+			label: label,
+		}, nil
+	case "edges":
+		return &Edges{
+			out:   os.Stdout,
+			err:   os.Stderr,
+			label: label,
+		}, nil
+	case "html":
+		return &HTML{
+			out:     os.Stdout,
+			err:     os.Stderr,
+			docs:    cmd.docs,
+			nocolor: cmd.nocolor,
+			label:   label,
+		}, nil
+	case "svg":
+		return &SVG{
+			out:      os.Stdout,
+			err:      os.Stderr,
+			docs:     cmd.docs,
+			clusters: cmd.clusters,
+			nocolor:  cmd.nocolor,
+			label:    label,
+		}, nil
+	case "png":
+		return &PNG{
+			out:      os.Stdout,
+			err:      os.Stderr,
+			clusters: cmd.clusters,
+			nocolor:  cmd.nocolor,
+			label:    label,
+		}, nil
+	case "ascii":
+		return &ASCII{
+			out:     os.Stdout,
+			err:     os.Stderr,
+			width:   cmd.width,
+			nocolor: cmd.nocolor,
+			label:   label,
+		}, nil
+	case "graphml":
+		return &GraphML{
+			out:     os.Stdout,
+			err:     os.Stderr,
+			label:   label,
+			nocolor: cmd.nocolor,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown output type %q", cmd.outputType)
+	}
+}
+
 type Format interface {
 	Write(*pkggraph.Graph) error
 }
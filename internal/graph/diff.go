@@ -0,0 +1,440 @@
+package graph
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/memory"
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/stat"
+	"github.com/loov/goda/internal/templates"
+)
+
+// Colors used for marking differences.
+const (
+	diffAddedColor     = "#2ca02c"
+	diffRemovedColor   = "#d62728"
+	diffChangedColor   = "#ff7f0e"
+	diffUnchangedColor = "#999999"
+)
+
+type DiffCommand struct {
+	Command
+
+	baseDir string
+	headDir string
+}
+
+func (*DiffCommand) Name() string     { return "graph-diff" }
+func (*DiffCommand) Synopsis() string { return "Compare dependency graphs." }
+func (*DiffCommand) Usage() string {
+	return `graph-diff <base-expr> -- <head-expr>:
+	Compare dependency graphs of two expressions.
+
+	The output contains the union of both graphs, where packages and imports
+	are colored as added (green), removed (red), changed (orange) or
+	unchanged (gray).
+
+	To compare revisions, check out the base revision into a separate
+	directory and use -basedir, for example:
+
+		git worktree add ../base main
+		goda graph-diff -basedir ../base ./... -- ./...
+
+Supported output types:
+
+	text - summary of the differences
+
+	All "graph" output types are supported as well.
+
+	Labels can use "diff" to access the differences of a package, e.g.:
+
+		{{ with diff . }}{{ .Status }} {{ .Stat.Go.Lines }}{{ end }}
+
+	See "help expr" for further information about expressions.
+	See "help format" for further information about formatting.
+`
+}
+
+func (cmd *DiffCommand) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.nocolor, "nocolor", false, "disable coloring")
+	f.StringVar(&cmd.docs, "docs", "https://pkg.go.dev/", "override the docs url to use")
+
+	f.StringVar(&cmd.outputType, "type", "text", "output type (text, dot, graphml, digraph, edges, tgf, mermaid, html, svg, png, ascii)")
+	f.StringVar(&cmd.labelFormat, "f", "", "label formatting")
+
+	f.BoolVar(&cmd.clusters, "cluster", false, "create clusters")
+	f.BoolVar(&cmd.shortID, "short", false, "use short package id-s inside clusters")
+
+	f.IntVar(&cmd.width, "width", 0, "maximum width of ascii output (default $COLUMNS or 120)")
+
+	f.StringVar(&cmd.baseDir, "basedir", "", "directory for evaluating the base expression")
+	f.StringVar(&cmd.headDir, "headdir", "", "directory for evaluating the head expression")
+}
+
+func (cmd *DiffCommand) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	args := f.Args()
+	split := slices.Index(args, "--")
+	if split < 0 {
+		fmt.Fprintln(os.Stderr, "missing \"--\" between base and head expressions")
+		return subcommands.ExitUsageError
+	}
+	baseExpr, headExpr := args[:split], args[split+1:]
+	if len(baseExpr) == 0 || len(headExpr) == 0 {
+		fmt.Fprintln(os.Stderr, "missing base or head expression")
+		return subcommands.ExitUsageError
+	}
+
+	if cmd.labelFormat == "" {
+		switch cmd.outputType {
+		case "dot":
+			cmd.labelFormat = `{{.ID}}\l{{with (diff .).Summary}}{{.}}\l{{end}}`
+		case "svg", "png":
+			cmd.labelFormat = `{{.ID}}{{with (diff .).Summary}}\n{{.}}{{end}}`
+		default:
+			cmd.labelFormat = `{{.ID}}{{with (diff .).Summary}} ({{.}}){{end}}`
+		}
+	}
+
+	var diffs map[*pkggraph.Node]*NodeDiff
+	label, err := templates.ParseWith(cmd.labelFormat, template.FuncMap{
+		"diff": func(n *pkggraph.Node) *NodeDiff { return diffs[n] },
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid label format: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	var format Format
+	if cmd.outputType != "text" {
+		format, err = cmd.newFormat(label)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	load := func(dir string, expr []string) (*pkggraph.Graph, error) {
+		result, err := pkgset.CalcIn(ctx, dir, expr)
+		if err != nil {
+			return nil, err
+		}
+		if !cmd.printStandard {
+			result = pkgset.Subtract(result, pkgset.Std())
+		}
+		return pkggraph.From(result), nil
+	}
+
+	base, err := load(cmd.baseDir, baseExpr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to evaluate base: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	head, err := load(cmd.headDir, headExpr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to evaluate head: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	diff := Diff(base, head)
+	diffs = diff.Nodes
+
+	if format == nil {
+		diff.WriteSummary(os.Stdout)
+		return subcommands.ExitSuccess
+	}
+	if err := format.Write(diff.Graph); err != nil {
+		fmt.Fprintf(os.Stderr, "error building graph: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+// DiffStatus describes how a package or an import changed.
+type DiffStatus string
+
+const (
+	Added     DiffStatus = "added"
+	Removed   DiffStatus = "removed"
+	Changed   DiffStatus = "changed"
+	Unchanged DiffStatus = "unchanged"
+)
+
+// NodeDiff describes the difference of a package between two graphs.
+type NodeDiff struct {
+	Status DiffStatus
+
+	// Base and Head are nil when the package is missing from the graph.
+	Base *pkggraph.Node
+	Head *pkggraph.Node
+
+	// Stat is the change of the package stats.
+	Stat stat.Stat
+	// Down is the change of the downstream stats.
+	Down stat.Stat
+}
+
+// Summary returns a short description of the changes.
+func (d *NodeDiff) Summary() string {
+	switch d.Status {
+	case Added, Removed:
+		return string(d.Status)
+	case Changed:
+		var changes []string
+		if d.Stat.Go.Lines != 0 {
+			changes = append(changes, signed(int64(d.Stat.Go.Lines))+" lines")
+		}
+		if d.Stat.Go.Size != 0 {
+			changes = append(changes, signedSize(d.Stat.Go.Size))
+		}
+		if d.Down.PackageCount != 0 {
+			changes = append(changes, signed(d.Down.PackageCount)+" deps")
+		}
+		if len(changes) == 0 {
+			if d.Down.Go.Lines != 0 {
+				return signed(int64(d.Down.Go.Lines)) + " lines in deps"
+			}
+			return "imports changed"
+		}
+		return strings.Join(changes, ", ")
+	}
+	return ""
+}
+
+// EdgeDiff describes an added or removed import.
+type EdgeDiff struct {
+	Status   DiffStatus
+	From, To *pkggraph.Node
+}
+
+// GraphDiff is the union of two graphs.
+type GraphDiff struct {
+	Base, Head *pkggraph.Graph
+
+	// Graph contains all packages and imports from base and head.
+	// Nodes use the stats from head, or from base for removed packages.
+	Graph *pkggraph.Graph
+	// Nodes contains the differences for nodes in Graph.
+	Nodes map[*pkggraph.Node]*NodeDiff
+	// Edges contains added and removed imports.
+	Edges []EdgeDiff
+}
+
+// Diff computes the union of base and head graphs.
+func Diff(base, head *pkggraph.Graph) *GraphDiff {
+	diff := &GraphDiff{
+		Base:  base,
+		Head:  head,
+		Graph: &pkggraph.Graph{Packages: map[string]*pkggraph.Node{}},
+		Nodes: map[*pkggraph.Node]*NodeDiff{},
+	}
+
+	nodeOf := func(id string) *pkggraph.Node {
+		if n, ok := diff.Graph.Packages[id]; ok {
+			return n
+		}
+
+		d := &NodeDiff{Base: base.Packages[id], Head: head.Packages[id]}
+		source := d.Head
+		switch {
+		case d.Base == nil:
+			d.Status = Added
+			d.Stat, d.Down = d.Head.Stat, d.Head.Down
+		case d.Head == nil:
+			d.Status = Removed
+			source = d.Base
+			d.Stat.Sub(d.Base.Stat)
+			d.Down.Sub(d.Base.Down)
+		default:
+			d.Status = Unchanged
+			d.Stat, d.Down = d.Head.Stat, d.Head.Down
+			d.Stat.Sub(d.Base.Stat)
+			d.Down.Sub(d.Base.Down)
+			if d.Stat != (stat.Stat{}) || d.Down != (stat.Stat{}) {
+				d.Status = Changed
+			}
+		}
+
+		n := &pkggraph.Node{
+			Package:   source.Package,
+			Color:     d.Status.color(),
+			EdgeColor: map[*pkggraph.Node]string{},
+			Stat:      source.Stat,
+			Up:        source.Up,
+			Down:      source.Down,
+			Errors:    source.Errors,
+
+			ImportSites: source.ImportSites,
+		}
+		diff.Graph.AddNode(n)
+		diff.Graph.Sorted = append(diff.Graph.Sorted, n)
+		diff.Graph.Stat.Add(n.Stat)
+		diff.Nodes[n] = d
+		return n
+	}
+
+	imports := func(g *pkggraph.Graph) map[[2]string]bool {
+		edges := map[[2]string]bool{}
+		for _, src := range g.Sorted {
+			nodeOf(src.ID)
+			for _, dst := range src.ImportsNodes {
+				edges[[2]string{src.ID, dst.ID}] = true
+			}
+		}
+		return edges
+	}
+	baseImports, headImports := imports(base), imports(head)
+	pkggraph.SortNodes(diff.Graph.Sorted)
+
+	addEdge := func(edge [2]string, status DiffStatus) {
+		src, dst := nodeOf(edge[0]), nodeOf(edge[1])
+		src.ImportsNodes = append(src.ImportsNodes, dst)
+		src.EdgeColor[dst] = status.color()
+		if status != Unchanged {
+			diff.Edges = append(diff.Edges, EdgeDiff{Status: status, From: src, To: dst})
+			if d := diff.Nodes[src]; d.Status == Unchanged {
+				d.Status = Changed
+				src.Color = Changed.color()
+			}
+		}
+	}
+	for edge := range headImports {
+		if baseImports[edge] {
+			addEdge(edge, Unchanged)
+		} else {
+			addEdge(edge, Added)
+		}
+	}
+	for edge := range baseImports {
+		if !headImports[edge] {
+			addEdge(edge, Removed)
+		}
+	}
+
+	diff.Graph.Link()
+	slices.SortFunc(diff.Edges, func(a, b EdgeDiff) int {
+		if r := strings.Compare(a.From.ID, b.From.ID); r != 0 {
+			return r
+		}
+		return strings.Compare(a.To.ID, b.To.ID)
+	})
+
+	return diff
+}
+
+// WriteSummary writes a textual summary of the differences.
+func (diff *GraphDiff) WriteSummary(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer func() { _ = tw.Flush() }()
+
+	countEdges := func(status DiffStatus) int {
+		count := 0
+		for _, e := range diff.Edges {
+			if e.Status == status {
+				count++
+			}
+		}
+		return count
+	}
+	countImports := func(g *pkggraph.Graph) int {
+		count := 0
+		for _, n := range g.Sorted {
+			count += len(n.ImportsNodes)
+		}
+		return count
+	}
+	byStatus := map[DiffStatus][]*pkggraph.Node{}
+	for _, n := range diff.Graph.Sorted {
+		status := diff.Nodes[n].Status
+		byStatus[status] = append(byStatus[status], n)
+	}
+
+	total := diff.Head.Stat
+	total.Sub(diff.Base.Stat)
+
+	fmt.Fprintf(tw, "packages:\t%v → %v\t(+%d -%d)\n",
+		diff.Base.PackageCount, diff.Head.PackageCount, len(byStatus[Added]), len(byStatus[Removed]))
+	fmt.Fprintf(tw, "imports:\t%v → %v\t(+%d -%d)\n",
+		countImports(diff.Base), countImports(diff.Head), countEdges(Added), countEdges(Removed))
+	fmt.Fprintf(tw, "lines:\t%v → %v\t(%v)\n",
+		diff.Base.Go.Lines, diff.Head.Go.Lines, signed(int64(total.Go.Lines)))
+	fmt.Fprintf(tw, "size:\t%v → %v\t(%v)\n",
+		diff.Base.Go.Size, diff.Head.Go.Size, signedSize(total.Go.Size))
+
+	for _, status := range []DiffStatus{Added, Removed, Changed} {
+		nodes := byStatus[status]
+		if len(nodes) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%v packages:\n", status)
+		for _, n := range nodes {
+			fmt.Fprintf(tw, "\t%v %v\t%v\n", status.symbol(), n.ID, diff.Nodes[n].Summary())
+		}
+	}
+
+	for _, status := range []DiffStatus{Added, Removed} {
+		if countEdges(status) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%v imports:\n", status)
+		for _, e := range diff.Edges {
+			if e.Status == status {
+				fmt.Fprintf(tw, "\t%v %v -> %v\n", status.symbol(), e.From.ID, e.To.ID)
+			}
+		}
+	}
+}
+
+func (status DiffStatus) color() string {
+	switch status {
+	case Added:
+		return diffAddedColor
+	case Removed:
+		return diffRemovedColor
+	case Changed:
+		return diffChangedColor
+	default:
+		return diffUnchangedColor
+	}
+}
+
+func (status DiffStatus) symbol() string {
+	switch status {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+func signed(v int64) string {
+	if v > 0 {
+		return "+" + strconv.FormatInt(v, 10)
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+func signedSize(v memory.Bytes) string {
+	if v > 0 {
+		return "+" + v.String()
+	}
+	return v.String()
+}
@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/stat"
+)
+
+type fixture struct {
+	lines   map[string]int
+	imports map[string][]string
+}
+
+func (fix fixture) graph() *pkggraph.Graph {
+	g := &pkggraph.Graph{Packages: map[string]*pkggraph.Node{}}
+	for id, lines := range fix.lines {
+		n := &pkggraph.Node{Package: &packages.Package{ID: id, PkgPath: id}}
+		n.Stat = stat.Stat{PackageCount: 1, Go: stat.Source{Files: 1, Lines: lines}}
+		g.AddNode(n)
+		g.Sorted = append(g.Sorted, n)
+		g.Stat.Add(n.Stat)
+	}
+	pkggraph.SortNodes(g.Sorted)
+	for from, to := range fix.imports {
+		for _, id := range to {
+			g.Packages[from].ImportsNodes = append(g.Packages[from].ImportsNodes, g.Packages[id])
+		}
+	}
+	g.Link()
+	return g
+}
+
+func TestDiff(t *testing.T) {
+	base := fixture{
+		lines:   map[string]int{"a": 10, "b": 20, "c": 5, "d": 3},
+		imports: map[string][]string{"a": {"b", "c"}, "d": {"b"}},
+	}.graph()
+	head := fixture{
+		lines:   map[string]int{"a": 10, "b": 25, "d": 3, "e": 7},
+		imports: map[string][]string{"a": {"b", "e"}, "d": {"b"}},
+	}.graph()
+
+	diff := Diff(base, head)
+
+	summaries := map[string]string{}
+	statuses := map[string]DiffStatus{}
+	for _, n := range diff.Graph.Sorted {
+		summaries[n.ID] = diff.Nodes[n].Summary()
+		statuses[n.ID] = diff.Nodes[n].Status
+	}
+	for id, exp := range map[string]struct {
+		status  DiffStatus
+		summary string
+	}{
+		"a": {Changed, "imports changed"},
+		"b": {Changed, "+5 lines"},
+		"c": {Removed, "removed"},
+		"d": {Unchanged, ""},
+		"e": {Added, "added"},
+	} {
+		if statuses[id] != exp.status || summaries[id] != exp.summary {
+			t.Errorf("%s: got %v %q, expected %v %q", id, statuses[id], summaries[id], exp.status, exp.summary)
+		}
+	}
+
+	var edges []string
+	for _, edge := range diff.Edges {
+		edges = append(edges, string(edge.Status)+" "+edge.From.ID+" -> "+edge.To.ID)
+	}
+	if got, exp := strings.Join(edges, ", "), "removed a -> c, added a -> e"; got != exp {
+		t.Errorf("edges: got %q, expected %q", got, exp)
+	}
+
+	a, b := diff.Graph.Packages["a"], diff.Graph.Packages["b"]
+	if got := ids(a.ImportsNodes); got != "b c e" {
+		t.Errorf("a imports: got %q", got)
+	}
+	if got := ids(b.ImportedByNodes); got != "a d" {
+		t.Errorf("b imported by: got %q", got)
+	}
+	if a.Metrics.Ce != 3 || b.Metrics.Ca != 2 {
+		t.Errorf("metrics: got a.Ce=%d b.Ca=%d", a.Metrics.Ce, b.Metrics.Ca)
+	}
+	if a.EdgeColor[diff.Graph.Packages["c"]] != diffRemovedColor {
+		t.Errorf("a -> c: expected removed color")
+	}
+	if diff.Graph.Go.Lines != 10+25+5+3+7 || diff.Graph.PackageCount != 5 {
+		t.Errorf("graph stat: got %d packages, %d lines", diff.Graph.PackageCount, diff.Graph.Go.Lines)
+	}
+
+	var summary bytes.Buffer
+	diff.WriteSummary(&summary)
+	for _, line := range []string{
+		"packages:  4 → 4",
+		"(+1 -1)",
+		"lines:     38 → 45",
+		"(+7)",
+	} {
+		if !strings.Contains(summary.String(), line) {
+			t.Errorf("summary does not contain %q:\n%s", line, summary.String())
+		}
+	}
+}
+
+func ids(nodes []*pkggraph.Node) string {
+	var xs []string
+	for _, n := range nodes {
+		xs = append(xs, n.ID)
+	}
+	return strings.Join(xs, " ")
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/loov/goda/internal/pkggraph"
 	"github.com/loov/goda/internal/pkgtree"
+	"github.com/loov/goda/internal/stat"
 )
 
 type Dot struct {
@@ -20,6 +21,8 @@ type Dot struct {
 	clusters bool
 	nocolor  bool
 	shortID  bool
+	// rank aligns nodes by "level" or "depth".
+	rank string
 
 	label *template.Template
 }
@@ -34,6 +37,11 @@ func (ctx *Dot) Label(p *pkggraph.Node) string {
 }
 
 func (ctx *Dot) ModuleLabel(mod *pkgtree.Module) string {
+	return moduleLabel(mod, " =>\\n")
+}
+
+// moduleLabel describes mod, using replaceSep before the replacement.
+func moduleLabel(mod *pkgtree.Module, replaceSep string) string {
 	lbl := mod.Mod.Path
 	if mod.Mod.Version != "" {
 		lbl += "@" + mod.Mod.Version
@@ -42,7 +50,7 @@ func (ctx *Dot) ModuleLabel(mod *pkgtree.Module) string {
 		lbl += " (local)"
 	}
 	if rep := mod.Mod.Replace; rep != nil {
-		lbl += " =>\\n" + rep.Path
+		lbl += replaceSep + rep.Path
 		if rep.Version != "" {
 			lbl += "@" + rep.Version
 		}
@@ -118,10 +126,11 @@ func (ctx *Dot) WriteRegular(graph *pkggraph.Graph) error {
 	for _, n := range graph.Sorted {
 		fmt.Fprintf(ctx.out, "    %v [label=\"%v\" %v %v];\n", pkgID(n), ctx.Label(n), ctx.Ref(n), ctx.colorOf(n))
 	}
+	ctx.writeRanks(graph)
 
 	for _, src := range graph.Sorted {
 		for _, dst := range src.ImportsNodes {
-			fmt.Fprintf(ctx.out, "    %v -> %v [%v];\n", pkgID(src), pkgID(dst), ctx.colorOf(dst))
+			fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=%v %v];\n", pkgID(src), pkgID(dst), edgeTooltip(src, dst), ctx.edgeColorOf(src, dst))
 		}
 	}
 
@@ -185,18 +194,19 @@ func (ctx *Dot) WriteClusters(graph *pkggraph.Graph) error {
 		tn.VisitChildren(visit)
 	}
 	root.VisitChildren(visit)
+	ctx.writeRanks(graph)
 
 	for _, src := range graph.Sorted {
 		srctree := lookup[src]
 		for _, dst := range src.ImportsNodes {
 			dstID := pkgID(dst)
 			dstTree := lookup[dst]
-			tooltip := src.ID + " -> " + dst.ID
+			tooltip := edgeTooltip(src, dst)
 
 			if isCluster[dst] && srctree.Parent != dstTree {
-				fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=\"%v\" lhead=%q %v];\n", pkgID(src), dstID, tooltip, "cluster_"+dst.ID, ctx.colorOf(dst))
+				fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=%v lhead=%q %v];\n", pkgID(src), dstID, tooltip, "cluster_"+dst.ID, ctx.edgeColorOf(src, dst))
 			} else {
-				fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=\"%v\" %v];\n", pkgID(src), dstID, tooltip, ctx.colorOf(dst))
+				fmt.Fprintf(ctx.out, "    %v -> %v [tooltip=%v %v];\n", pkgID(src), dstID, tooltip, ctx.edgeColorOf(src, dst))
 			}
 		}
 	}
@@ -204,6 +214,47 @@ func (ctx *Dot) WriteClusters(graph *pkggraph.Graph) error {
 	return nil
 }
 
+// writeRanks places nodes with the same level or depth on the same rank.
+func (ctx *Dot) writeRanks(graph *pkggraph.Graph) {
+	var rankOf func(*pkggraph.Node) int
+	switch ctx.rank {
+	case "level":
+		rankOf = (*pkggraph.Node).Level
+	case "depth":
+		rankOf = (*pkggraph.Node).Depth
+	default:
+		return
+	}
+
+	ranks := map[int][]string{}
+	maxRank := 0
+	for _, n := range graph.Sorted {
+		rank := rankOf(n)
+		ranks[rank] = append(ranks[rank], pkgID(n))
+		maxRank = max(maxRank, rank)
+	}
+	for rank := 0; rank <= maxRank; rank++ {
+		if ids := ranks[rank]; len(ids) > 0 {
+			fmt.Fprintf(ctx.out, "    { rank=same; %v; }\n", strings.Join(ids, "; "))
+		}
+	}
+}
+
+// edgeTooltip describes the edge and the locations of the import specs.
+func edgeTooltip(src, dst *pkggraph.Node) string {
+	tooltip := src.ID + " -> " + dst.ID
+	for _, site := range src.SitesOf(dst) {
+		tooltip += "\n" + site.Position()
+		if site.Kind != stat.ImportRegular {
+			tooltip += " (" + string(site.Kind) + ")"
+		}
+	}
+	return `"` + dotEscaper.Replace(tooltip) + `"`
+}
+
+// dotEscaper escapes text for a quoted dot string, where "\n" is a line break.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
 func (ctx *Dot) colorOf(p *pkggraph.Node) string {
 	if p.Color != "" {
 		return "color=" + strconv.Quote(p.Color)
@@ -216,3 +267,10 @@ func (ctx *Dot) colorOf(p *pkggraph.Node) string {
 	hue := float64(uint(hash[0])<<8|uint(hash[1])) / 0xFFFF
 	return "color=\"" + hslahex(hue, 0.9, 0.3, 0.7) + "\""
 }
+
+func (ctx *Dot) edgeColorOf(src, dst *pkggraph.Node) string {
+	if color := src.EdgeColor[dst]; color != "" {
+		return "color=" + strconv.Quote(color)
+	}
+	return ctx.colorOf(dst)
+}
@@ -0,0 +1,29 @@
+package graph
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/stat"
+)
+
+func TestEdgeTooltip(t *testing.T) {
+	dst := &pkggraph.Node{Package: &packages.Package{ID: "example.com/ünicode"}}
+	src := &pkggraph.Node{
+		Package: &packages.Package{
+			ID:      "a",
+			Imports: map[string]*packages.Package{"example.com/ünicode": dst.Package},
+		},
+		ImportSites: []stat.ImportSite{
+			{Path: "example.com/ünicode", File: `C:\src\"a".go`, Line: 3, Column: 2, Kind: stat.ImportBlank},
+		},
+	}
+
+	got := edgeTooltip(src, dst)
+	exp := `"a -> example.com/ünicode\nC:\\src\\\"a\".go:3:2 (blank)"`
+	if got != exp {
+		t.Errorf("got %s, expected %s", got, exp)
+	}
+}
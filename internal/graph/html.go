@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/stat"
+)
+
+var (
+	//go:embed viewer/viewer.html
+	htmlViewerPage string
+	//go:embed viewer/viewer.js
+	htmlViewerScript string
+	//go:embed viewer/viewer.css
+	htmlViewerStyle string
+)
+
+var htmlViewer = template.Must(template.New("").Parse(htmlViewerPage))
+
+// HTML writes a self-contained interactive viewer.
+type HTML struct {
+	out io.Writer
+	err io.Writer
+
+	docs    string
+	nocolor bool
+
+	label *texttemplate.Template
+}
+
+type htmlGraph struct {
+	Nodes []htmlNode `json:"nodes"`
+}
+
+type htmlNode struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Module  string `json:"module,omitempty"`
+	Color   string `json:"color,omitempty"`
+	Docs    string `json:"docs,omitempty"`
+	Imports []int  `json:"imports,omitempty"`
+	// ImportColors overrides colors of the edges to Imports.
+	ImportColors []string `json:"importColors,omitempty"`
+
+	Stat stat.Stat `json:"stat"`
+	Up   stat.Stat `json:"up"`
+	Down stat.Stat `json:"down"`
+}
+
+func (ctx *HTML) Label(p *pkggraph.Node) string {
+	var labelText strings.Builder
+	err := ctx.label.Execute(&labelText, p)
+	if err != nil {
+		fmt.Fprintf(ctx.err, "template error: %v\n", err)
+	}
+	return labelText.String()
+}
+
+func (ctx *HTML) Write(graph *pkggraph.Graph) error {
+	index := map[*pkggraph.Node]int{}
+	for i, n := range graph.Sorted {
+		index[n] = i
+	}
+
+	data := htmlGraph{}
+	for _, n := range graph.Sorted {
+		node := htmlNode{
+			ID:    n.ID,
+			Label: ctx.Label(n),
+			Color: ctx.colorOf(n),
+			Stat:  n.Stat,
+			Up:    n.Up,
+			Down:  n.Down,
+		}
+		if n.Module != nil {
+			node.Module = n.Module.Path
+		}
+		if ctx.docs != "" {
+			node.Docs = ctx.docs + n.ID
+		}
+		for _, dst := range n.ImportsNodes {
+			node.Imports = append(node.Imports, index[dst])
+			if color := n.EdgeColor[dst]; color != "" {
+				if node.ImportColors == nil {
+					node.ImportColors = make([]string, len(n.ImportsNodes))
+				}
+				node.ImportColors[len(node.Imports)-1] = color
+			}
+		}
+		data.Nodes = append(data.Nodes, node)
+	}
+
+	return htmlViewer.Execute(ctx.out, map[string]any{
+		"Graph":  data,
+		"Script": template.JS(htmlViewerScript),
+		"Style":  template.CSS(htmlViewerStyle),
+	})
+}
+
+func (ctx *HTML) colorOf(p *pkggraph.Node) string {
+	if p.Color != "" {
+		return p.Color
+	}
+	if ctx.nocolor {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(p.PkgPath))
+	hue := float64(uint(hash[0])<<8|uint(hash[1])) / 0xFFFF
+	return hslhex(hue, 0.6, 0.45)
+}
@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/loov/goda/internal/graph/layout"
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgtree"
+)
+
+// layered is a pkggraph.Graph with a computed layout.
+type layered struct {
+	*layout.Graph
+
+	nodes         map[*layout.Node]*pkggraph.Node
+	labels        map[*layout.Node][]string
+	clusterLabels map[string]string
+}
+
+// metrics describes how labels are measured for the layout.
+type metrics struct {
+	charWidth  float64
+	lineHeight float64
+	padX       float64
+	padY       float64
+}
+
+// layoutGraph computes a layered layout for graph.
+func layoutGraph(graph *pkggraph.Graph, label func(*pkggraph.Node) string, clusters bool, m metrics, opts layout.Options) (*layered, error) {
+	lg := &layered{
+		Graph:         &layout.Graph{},
+		nodes:         map[*layout.Node]*pkggraph.Node{},
+		labels:        map[*layout.Node][]string{},
+		clusterLabels: map[string]string{},
+	}
+
+	clusterOf := map[*pkggraph.Node]string{}
+	if clusters {
+		root, err := pkgtree.From(graph)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct cluster tree: %v", err)
+		}
+		for n, tp := range root.LookupTable() {
+			switch parent := tp.Parent.(type) {
+			case *pkgtree.Module:
+				clusterOf[n] = parent.Path()
+				lg.clusterLabels[parent.Path()] = moduleLabel(parent, " => ")
+			case *pkgtree.Repo:
+				if !tp.OnlyChild() {
+					clusterOf[n] = parent.Path()
+					lg.clusterLabels[parent.Path()] = parent.Path()
+				}
+			}
+		}
+	}
+
+	lookup := map[*pkggraph.Node]*layout.Node{}
+	for _, n := range graph.Sorted {
+		lines := labelLines(label(n))
+		width := 0
+		for _, line := range lines {
+			width = max(width, utf8.RuneCountInString(line))
+		}
+
+		ln := lg.AddNode(n.ID,
+			float64(width)*m.charWidth+2*m.padX,
+			float64(len(lines))*m.lineHeight+2*m.padY,
+			clusterOf[n])
+		lookup[n] = ln
+		lg.nodes[ln] = n
+		lg.labels[ln] = lines
+	}
+
+	for _, src := range graph.Sorted {
+		for _, dst := range src.ImportsNodes {
+			lg.AddEdge(lookup[src], lookup[dst])
+		}
+	}
+
+	layout.Layered(lg.Graph, opts)
+	return lg, nil
+}
+
+// labelLines splits label into lines, also handling dot style line breaks.
+func labelLines(label string) []string {
+	label = strings.NewReplacer(`\n`, "\n", `\l`, "\n", `\r`, "\n").Replace(label)
+	lines := strings.Split(strings.TrimRight(label, "\n"), "\n")
+	if len(lines) == 0 {
+		lines = []string{""}
+	}
+	return lines
+}
@@ -0,0 +1,431 @@
+// Package layout implements a layered (Sugiyama-style) graph layout.
+//
+// The layout flows from left to right: every edge points from a lower
+// layer to a higher layer, except edges that had to be reversed to break
+// cycles.
+package layout
+
+import (
+	"math"
+	"sort"
+)
+
+// Graph is the input and output of the layout.
+type Graph struct {
+	Nodes []*Node
+	Edges []*Edge
+
+	// Computed by Layered.
+	Layers   [][]*Node
+	Clusters []*Cluster
+	Width    float64
+	Height   float64
+}
+
+// Node is a box in the layout.
+type Node struct {
+	ID     string
+	Width  float64
+	Height float64
+	// Cluster groups nodes into a shared horizontal band.
+	Cluster string
+
+	// Computed by Layered.
+	Layer int
+	Order int
+	X, Y  float64 // top-left corner
+	Dummy bool    // dummy nodes are inserted for edges spanning several layers
+
+	in, out []*Node
+	bary    float64
+}
+
+// Edge is a directed connection between two nodes.
+type Edge struct {
+	From, To *Node
+
+	// Computed by Layered.
+	Reversed bool    // edge was reversed to break a cycle
+	Path     []*Node // nodes along the edge in layer order, including dummies
+	Points   []Point // route in layer order, from the right side of the first node
+}
+
+// Point is a coordinate in the layout.
+type Point struct{ X, Y float64 }
+
+// Cluster is the band containing nodes with the same cluster name.
+type Cluster struct {
+	ID    string
+	Nodes []*Node
+
+	X, Y          float64
+	Width, Height float64
+}
+
+// Options configures the spacing of the layout.
+type Options struct {
+	LayerSpacing   float64 // horizontal space between layers
+	NodeSpacing    float64 // vertical space between nodes
+	ClusterPadding float64 // space between cluster border and nodes
+	ClusterLabel   float64 // space reserved for the cluster label
+	Iterations     int     // crossing reduction sweeps
+}
+
+// AddNode adds a new node to the graph.
+func (g *Graph) AddNode(id string, width, height float64, cluster string) *Node {
+	n := &Node{ID: id, Width: width, Height: height, Cluster: cluster}
+	g.Nodes = append(g.Nodes, n)
+	return n
+}
+
+// AddEdge adds a new edge to the graph.
+func (g *Graph) AddEdge(from, to *Node) *Edge {
+	e := &Edge{From: from, To: to}
+	g.Edges = append(g.Edges, e)
+	return e
+}
+
+// Layered computes the layout of g.
+func Layered(g *Graph, opts Options) {
+	if opts.Iterations <= 0 {
+		opts.Iterations = 8
+	}
+
+	nodes := append([]*Node{}, g.Nodes...)
+	for _, n := range nodes {
+		n.in, n.out = nil, nil
+		n.Dummy = false
+	}
+
+	breakCycles(g)
+	assignLayers(nodes)
+	g.Layers = splitLongEdges(g, nodes)
+	g.Clusters = orderLayers(g.Layers, opts.Iterations)
+	assignCoordinates(g, opts)
+	routeEdges(g)
+}
+
+// breakCycles reverses edges which would form a cycle.
+func breakCycles(g *Graph) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[*Node]int{}
+	outgoing := map[*Node][]*Edge{}
+	for _, e := range g.Edges {
+		e.Reversed = false
+		outgoing[e.From] = append(outgoing[e.From], e)
+	}
+
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		state[n] = visiting
+		for _, e := range outgoing[n] {
+			switch state[e.To] {
+			case unvisited:
+				visit(e.To)
+			case visiting:
+				e.Reversed = true
+			}
+		}
+		state[n] = visited
+	}
+	for _, n := range g.Nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+
+	for _, e := range g.Edges {
+		from, to := e.ends()
+		if from == to {
+			continue
+		}
+		from.out = append(from.out, to)
+		to.in = append(to.in, from)
+	}
+}
+
+// ends returns the edge endpoints in layer order.
+func (e *Edge) ends() (from, to *Node) {
+	if e.Reversed {
+		return e.To, e.From
+	}
+	return e.From, e.To
+}
+
+// assignLayers places each node one layer after its furthest predecessor.
+func assignLayers(nodes []*Node) {
+	done := map[*Node]bool{}
+	var visit func(n *Node) int
+	visit = func(n *Node) int {
+		if done[n] {
+			return n.Layer
+		}
+		layer := 0
+		for _, p := range n.in {
+			layer = max(layer, visit(p)+1)
+		}
+		n.Layer = layer
+		done[n] = true
+		return layer
+	}
+	for _, n := range nodes {
+		visit(n)
+	}
+}
+
+// splitLongEdges inserts dummy nodes such that every edge spans a single layer.
+func splitLongEdges(g *Graph, nodes []*Node) [][]*Node {
+	for _, n := range nodes {
+		n.in, n.out = nil, nil
+	}
+
+	maxLayer := 0
+	for _, n := range nodes {
+		maxLayer = max(maxLayer, n.Layer)
+	}
+	layers := make([][]*Node, maxLayer+1)
+	for _, n := range nodes {
+		layers[n.Layer] = append(layers[n.Layer], n)
+	}
+
+	for _, e := range g.Edges {
+		from, to := e.ends()
+		e.Path = []*Node{from}
+		if from == to {
+			e.Path = append(e.Path, to)
+			continue
+		}
+
+		prev := from
+		for layer := from.Layer + 1; layer < to.Layer; layer++ {
+			dummy := &Node{
+				ID:      from.ID + " -> " + to.ID,
+				Cluster: from.Cluster,
+				Layer:   layer,
+				Dummy:   true,
+			}
+			layers[layer] = append(layers[layer], dummy)
+			link(prev, dummy)
+			e.Path = append(e.Path, dummy)
+			prev = dummy
+		}
+		link(prev, to)
+		e.Path = append(e.Path, to)
+	}
+
+	return layers
+}
+
+func link(from, to *Node) {
+	from.out = append(from.out, to)
+	to.in = append(to.in, from)
+}
+
+// orderLayers reduces edge crossings using barycenter heuristic, while
+// keeping nodes of the same cluster next to each other.
+func orderLayers(layers [][]*Node, iterations int) []*Cluster {
+	clusterByID := map[string]*Cluster{}
+	var clusters []*Cluster
+	for _, layer := range layers {
+		for _, n := range layer {
+			c, ok := clusterByID[n.Cluster]
+			if !ok {
+				c = &Cluster{ID: n.Cluster}
+				clusterByID[n.Cluster] = c
+				clusters = append(clusters, c)
+			}
+			if !n.Dummy {
+				c.Nodes = append(c.Nodes, n)
+			}
+		}
+	}
+	sort.SliceStable(clusters, func(i, k int) bool { return clusters[i].ID < clusters[k].ID })
+
+	rank := map[string]int{}
+	rerank := func() {
+		for i, c := range clusters {
+			rank[c.ID] = i
+		}
+	}
+	rerank()
+
+	sortLayer := func(layer []*Node) {
+		sort.SliceStable(layer, func(i, k int) bool {
+			a, b := layer[i], layer[k]
+			if rank[a.Cluster] != rank[b.Cluster] {
+				return rank[a.Cluster] < rank[b.Cluster]
+			}
+			return a.bary < b.bary
+		})
+		for i, n := range layer {
+			n.Order = i
+		}
+	}
+
+	for _, layer := range layers {
+		sort.SliceStable(layer, func(i, k int) bool { return layer[i].ID < layer[k].ID })
+		for i, n := range layer {
+			n.bary = float64(i)
+		}
+		sortLayer(layer)
+	}
+
+	barycenter := func(n *Node, neighbors []*Node) float64 {
+		if len(neighbors) == 0 {
+			return float64(n.Order)
+		}
+		total := 0.0
+		for _, x := range neighbors {
+			total += float64(x.Order)
+		}
+		return total / float64(len(neighbors))
+	}
+
+	for iter := 0; iter < iterations; iter++ {
+		if iter%2 == 0 {
+			for _, layer := range layers[1:] {
+				for _, n := range layer {
+					n.bary = barycenter(n, n.in)
+				}
+				sortLayer(layer)
+			}
+		} else {
+			for l := len(layers) - 2; l >= 0; l-- {
+				for _, n := range layers[l] {
+					n.bary = barycenter(n, n.out)
+				}
+				sortLayer(layers[l])
+			}
+		}
+
+		// Move clusters closer to the nodes they are connected to.
+		if len(clusters) > 1 && iter%2 == 1 {
+			position := map[string]float64{}
+			count := map[string]float64{}
+			for _, layer := range layers {
+				for _, n := range layer {
+					position[n.Cluster] += n.bary / float64(max(len(layer), 1))
+					count[n.Cluster]++
+				}
+			}
+			sort.SliceStable(clusters, func(i, k int) bool {
+				a, b := clusters[i].ID, clusters[k].ID
+				return position[a]/count[a] < position[b]/count[b]
+			})
+			rerank()
+			for _, layer := range layers {
+				sortLayer(layer)
+			}
+		}
+	}
+
+	return clusters
+}
+
+// assignCoordinates places layers in columns and clusters in separate bands.
+func assignCoordinates(g *Graph, opts Options) {
+	layerX := make([]float64, len(g.Layers))
+	layerWidth := make([]float64, len(g.Layers))
+	margin := 0.0
+	for _, c := range g.Clusters {
+		if c.ID != "" {
+			margin = opts.ClusterPadding
+		}
+	}
+
+	x := margin
+	for l, layer := range g.Layers {
+		for _, n := range layer {
+			layerWidth[l] = max(layerWidth[l], n.Width)
+		}
+		layerX[l] = x
+		x += layerWidth[l] + opts.LayerSpacing
+	}
+	g.Width = max(x-opts.LayerSpacing, 0) + margin
+
+	y := 0.0
+	for _, c := range g.Clusters {
+		padding, label := 0.0, 0.0
+		if c.ID != "" {
+			padding, label = opts.ClusterPadding, opts.ClusterLabel
+		}
+
+		// The band is as high as the tallest column of the cluster.
+		bandHeight := 0.0
+		for _, layer := range g.Layers {
+			height := -opts.NodeSpacing
+			for _, n := range layer {
+				if n.Cluster == c.ID {
+					height += n.Height + opts.NodeSpacing
+				}
+			}
+			bandHeight = max(bandHeight, height)
+		}
+
+		top := y + padding + label
+		for _, layer := range g.Layers {
+			height := -opts.NodeSpacing
+			for _, n := range layer {
+				if n.Cluster == c.ID {
+					height += n.Height + opts.NodeSpacing
+				}
+			}
+			ny := top + (bandHeight-height)/2
+			for _, n := range layer {
+				if n.Cluster != c.ID {
+					continue
+				}
+				n.X = layerX[n.Layer]
+				n.Y = ny
+				ny += n.Height + opts.NodeSpacing
+			}
+		}
+
+		c.Y = y
+		c.Height = bandHeight + 2*padding + label
+		c.X, c.Width = 0, 0
+		if len(c.Nodes) > 0 {
+			left, right := math.Inf(1), math.Inf(-1)
+			for _, n := range c.Nodes {
+				left = min(left, n.X)
+				right = max(right, n.X+n.Width)
+			}
+			c.X = left - padding
+			c.Width = right - left + 2*padding
+		}
+
+		y += c.Height + opts.NodeSpacing
+	}
+	g.Height = max(y-opts.NodeSpacing, 0)
+
+	// Dummy nodes span the full width of the layer.
+	for l, layer := range g.Layers {
+		for _, n := range layer {
+			if n.Dummy {
+				n.X = layerX[l]
+				n.Width = layerWidth[l]
+			}
+		}
+	}
+}
+
+// routeEdges computes points along each edge.
+func routeEdges(g *Graph) {
+	center := func(n *Node) float64 { return n.Y + n.Height/2 }
+	for _, e := range g.Edges {
+		e.Points = e.Points[:0]
+		first := e.Path[0]
+		e.Points = append(e.Points, Point{first.X + first.Width, center(first)})
+		for _, n := range e.Path[1 : len(e.Path)-1] {
+			e.Points = append(e.Points,
+				Point{n.X, center(n)},
+				Point{n.X + n.Width, center(n)})
+		}
+		last := e.Path[len(e.Path)-1]
+		e.Points = append(e.Points, Point{last.X, center(last)})
+	}
+}
@@ -0,0 +1,60 @@
+package layout
+
+import "testing"
+
+func TestLayered(t *testing.T) {
+	g := &Graph{}
+	a := g.AddNode("a", 10, 10, "x")
+	b := g.AddNode("b", 20, 10, "x")
+	c := g.AddNode("c", 10, 10, "y")
+	d := g.AddNode("d", 10, 10, "y")
+	g.AddEdge(a, b)
+	g.AddEdge(b, c)
+	g.AddEdge(a, c)
+	g.AddEdge(a, d)
+	cycle := g.AddEdge(d, a)
+
+	Layered(g, Options{LayerSpacing: 5, NodeSpacing: 5, ClusterPadding: 2})
+
+	if !cycle.Reversed {
+		t.Errorf("expected %v -> %v to be reversed", cycle.From.ID, cycle.To.ID)
+	}
+
+	for _, e := range g.Edges {
+		from, to := e.ends()
+		if from.Layer >= to.Layer {
+			t.Errorf("edge %v -> %v does not go forward: %d -> %d", from.ID, to.ID, from.Layer, to.Layer)
+		}
+		if len(e.Path) != to.Layer-from.Layer+1 {
+			t.Errorf("edge %v -> %v has invalid path length %d", from.ID, to.ID, len(e.Path))
+		}
+		if len(e.Points) != 2*len(e.Path)-2 {
+			t.Errorf("edge %v -> %v has invalid point count %d", from.ID, to.ID, len(e.Points))
+		}
+	}
+
+	for _, layer := range g.Layers {
+		for i, n := range layer {
+			for _, m := range layer[i+1:] {
+				if n.Y < m.Y+m.Height && m.Y < n.Y+n.Height {
+					t.Errorf("nodes %q and %q overlap", n.ID, m.ID)
+				}
+			}
+		}
+	}
+
+	if len(g.Clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(g.Clusters))
+	}
+	x, y := g.Clusters[0], g.Clusters[1]
+	if x.Y < y.Y+y.Height && y.Y < x.Y+x.Height {
+		t.Errorf("clusters %q and %q overlap", x.ID, y.ID)
+	}
+	for _, cluster := range g.Clusters {
+		for _, n := range cluster.Nodes {
+			if n.Y < cluster.Y || n.Y+n.Height > cluster.Y+cluster.Height {
+				t.Errorf("node %q outside of cluster %q", n.ID, cluster.ID)
+			}
+		}
+	}
+}
@@ -73,7 +73,7 @@ func (ctx *Mermaid) WriteRegular(graph *pkggraph.Graph) error {
 		for _, dst := range src.ImportsNodes {
 			dstid := ctx.PkgID(dst)
 			fmt.Fprintf(ctx.out, "    %v --> %v\n", srcid, dstid)
-			if color := ctx.strokeColorOf(dst); color != "" {
+			if color := ctx.edgeColorOf(src, dst); color != "" {
 				fmt.Fprintf(ctx.out, "    linkStyle %v stroke:%v\n", linkIndex, color)
 			}
 			linkIndex++
@@ -108,3 +108,10 @@ func (ctx *Mermaid) strokeColorOf(p *pkggraph.Node) string {
 	hue := float64(uint(hash[0])<<8|uint(hash[1])) / 0xFFFF
 	return hslahex(hue, 0.6, 0.3, 0.8)
 }
+
+func (ctx *Mermaid) edgeColorOf(src, dst *pkggraph.Node) string {
+	if color := src.EdgeColor[dst]; color != "" {
+		return color
+	}
+	return ctx.strokeColorOf(dst)
+}
@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+
+	"github.com/loov/goda/internal/graph/layout"
+	"github.com/loov/goda/internal/pkggraph"
+)
+
+// maxPNGPixels limits the size of the rendered image.
+const maxPNGPixels = 1 << 26
+
+type PNG struct {
+	out io.Writer
+	err io.Writer
+
+	clusters bool
+	nocolor  bool
+
+	label *template.Template
+}
+
+func (ctx *PNG) Label(p *pkggraph.Node) string {
+	var labelText strings.Builder
+	err := ctx.label.Execute(&labelText, p)
+	if err != nil {
+		fmt.Fprintf(ctx.err, "template error: %v\n", err)
+	}
+	return labelText.String()
+}
+
+func (ctx *PNG) Write(graph *pkggraph.Graph) error {
+	lg, err := layoutGraph(graph, ctx.Label, ctx.clusters, layeredMetrics, layeredOptions)
+	if err != nil {
+		return err
+	}
+
+	width := int(math.Ceil(lg.Width)) + 2*layeredMargin
+	height := int(math.Ceil(lg.Height)) + 2*layeredMargin
+	if width*height > maxPNGPixels {
+		return fmt.Errorf("graph is too large for png (%dx%d), use svg or html output instead", width, height)
+	}
+
+	c := &canvas{
+		img:    image.NewRGBA(image.Rect(0, 0, width, height)),
+		offset: layout.Point{X: layeredMargin, Y: layeredMargin},
+	}
+	draw.Draw(c.img, c.img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for _, cluster := range lg.Clusters {
+		if cluster.ID == "" || len(cluster.Nodes) == 0 {
+			continue
+		}
+		c.fillRect(cluster.X, cluster.Y, cluster.Width, cluster.Height, color.RGBA{0xf6, 0xf6, 0xf6, 0xff})
+		c.strokeRect(cluster.X, cluster.Y, cluster.Width, cluster.Height, 1, color.RGBA{0xbb, 0xbb, 0xbb, 0xff})
+		c.text(cluster.X+4, cluster.Y+2, lg.clusterLabels[cluster.ID])
+	}
+
+	for _, e := range lg.Edges {
+		col := ctx.edgeColorOf(lg.nodes[e.From], lg.nodes[e.To])
+		points := e.Points
+		for i := 1; i < len(points); i++ {
+			c.curve(points[i-1], points[i], 1.5, col)
+		}
+		tip, dir := arrowOf(e)
+		c.polygon(col,
+			tip,
+			layout.Point{X: tip.X - 7*dir, Y: tip.Y - 3.5},
+			layout.Point{X: tip.X - 7*dir, Y: tip.Y + 3.5})
+	}
+
+	for _, ln := range lg.Nodes {
+		n := lg.nodes[ln]
+		c.fillRect(ln.X, ln.Y, ln.Width, ln.Height, color.White)
+		c.strokeRect(ln.X, ln.Y, ln.Width, ln.Height, 2, ctx.colorOf(n))
+		for i, line := range lg.labels[ln] {
+			c.text(ln.X+layeredMetrics.padX, ln.Y+layeredMetrics.padY+float64(i)*layeredMetrics.lineHeight, line)
+		}
+	}
+
+	return png.Encode(ctx.out, c.img)
+}
+
+func (ctx *PNG) colorOf(p *pkggraph.Node) color.RGBA {
+	if p.Color != "" {
+		if c, ok := parseColor(p.Color); ok {
+			return c
+		}
+	}
+	if ctx.nocolor {
+		return color.RGBA{0, 0, 0, 0xff}
+	}
+
+	hash := sha256.Sum256([]byte(p.PkgPath))
+	hue := float64(uint(hash[0])<<8|uint(hash[1])) / 0xFFFF
+	r, g, b, _ := hsla(hue, 0.9, 0.3, 1)
+	return color.RGBA{sat8(r), sat8(g), sat8(b), 0xff}
+}
+
+func (ctx *PNG) edgeColorOf(src, dst *pkggraph.Node) color.RGBA {
+	if c, ok := parseColor(src.EdgeColor[dst]); ok {
+		return c
+	}
+	return ctx.colorOf(dst)
+}
+
+// parseColor parses a color name or a hex color.
+func parseColor(s string) (color.RGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := colornames.Map[s]; ok {
+		return c, true
+	}
+
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok {
+		return color.RGBA{}, false
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	// Colors are drawn opaque, to avoid darker joints between line segments.
+	return color.RGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), 0xff}, true
+}
+
+// canvas implements the drawing primitives for png output.
+type canvas struct {
+	img    *image.RGBA
+	offset layout.Point
+	raster vector.Rasterizer
+}
+
+func (c *canvas) fillRect(x, y, w, h float64, col color.Color) {
+	r := image.Rect(
+		int(math.Round(x+c.offset.X)), int(math.Round(y+c.offset.Y)),
+		int(math.Round(x+w+c.offset.X)), int(math.Round(y+h+c.offset.Y)))
+	draw.Draw(c.img, r, image.NewUniform(col), image.Point{}, draw.Src)
+}
+
+func (c *canvas) strokeRect(x, y, w, h, width float64, col color.Color) {
+	c.fillRect(x, y, w, width, col)
+	c.fillRect(x, y+h-width, w, width, col)
+	c.fillRect(x, y, width, h, col)
+	c.fillRect(x+w-width, y, width, h, col)
+}
+
+func (c *canvas) text(x, y float64, s string) {
+	face := basicfont.Face7x13
+	d := font.Drawer{
+		Dst:  c.img,
+		Src:  image.Black,
+		Face: face,
+		Dot:  fixed.P(int(math.Round(x+c.offset.X)), int(math.Round(y+c.offset.Y))+face.Ascent),
+	}
+	d.DrawString(s)
+}
+
+// curve draws a line with horizontal tangents at both ends.
+func (c *canvas) curve(a, b layout.Point, width float64, col color.RGBA) {
+	if a.Y == b.Y {
+		c.line(a, b, width, col)
+		return
+	}
+
+	const steps = 16
+	mid := (a.X + b.X) / 2
+	prev := a
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / steps
+		u := 1 - t
+		next := layout.Point{
+			X: u*u*u*a.X + 3*u*u*t*mid + 3*u*t*t*mid + t*t*t*b.X,
+			Y: u*u*u*a.Y + 3*u*u*t*a.Y + 3*u*t*t*b.Y + t*t*t*b.Y,
+		}
+		c.line(prev, next, width, col)
+		prev = next
+	}
+}
+
+// line draws a single line segment.
+func (c *canvas) line(a, b layout.Point, width float64, col color.RGBA) {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return
+	}
+	nx, ny := -dy/length*width/2, dx/length*width/2
+	c.polygon(col,
+		layout.Point{X: a.X + nx, Y: a.Y + ny},
+		layout.Point{X: b.X + nx, Y: b.Y + ny},
+		layout.Point{X: b.X - nx, Y: b.Y - ny},
+		layout.Point{X: a.X - nx, Y: a.Y - ny})
+}
+
+// polygon fills a convex polygon.
+func (c *canvas) polygon(col color.RGBA, points ...layout.Point) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range points {
+		minX, minY = min(minX, p.X), min(minY, p.Y)
+		maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+	}
+
+	// Rasterize only the bounding box of the polygon.
+	bounds := image.Rect(
+		int(math.Floor(minX+c.offset.X)), int(math.Floor(minY+c.offset.Y)),
+		int(math.Ceil(maxX+c.offset.X))+1, int(math.Ceil(maxY+c.offset.Y))+1)
+	bounds = bounds.Intersect(c.img.Bounds())
+	if bounds.Empty() {
+		return
+	}
+
+	origin := layout.Point{X: float64(bounds.Min.X) - c.offset.X, Y: float64(bounds.Min.Y) - c.offset.Y}
+	c.raster.Reset(bounds.Dx(), bounds.Dy())
+	c.raster.MoveTo(float32(points[0].X-origin.X), float32(points[0].Y-origin.Y))
+	for _, p := range points[1:] {
+		c.raster.LineTo(float32(p.X-origin.X), float32(p.Y-origin.Y))
+	}
+	c.raster.ClosePath()
+	c.raster.Draw(c.img, bounds, image.NewUniform(col), image.Point{})
+}
@@ -0,0 +1,163 @@
+package graph
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/loov/goda/internal/graph/layout"
+	"github.com/loov/goda/internal/pkggraph"
+)
+
+// layeredMetrics are used for measuring labels in svg and png output,
+// they match the basicfont.Face7x13 font.
+var layeredMetrics = metrics{
+	charWidth:  7,
+	lineHeight: 14,
+	padX:       6,
+	padY:       4,
+}
+
+var layeredOptions = layout.Options{
+	LayerSpacing:   60,
+	NodeSpacing:    10,
+	ClusterPadding: 10,
+	ClusterLabel:   16,
+}
+
+// layeredMargin is the space around the graph.
+const layeredMargin = 10
+
+type SVG struct {
+	out io.Writer
+	err io.Writer
+
+	docs     string
+	clusters bool
+	nocolor  bool
+
+	label *template.Template
+}
+
+func (ctx *SVG) Label(p *pkggraph.Node) string {
+	var labelText strings.Builder
+	err := ctx.label.Execute(&labelText, p)
+	if err != nil {
+		fmt.Fprintf(ctx.err, "template error: %v\n", err)
+	}
+	return labelText.String()
+}
+
+func (ctx *SVG) Write(graph *pkggraph.Graph) error {
+	lg, err := layoutGraph(graph, ctx.Label, ctx.clusters, layeredMetrics, layeredOptions)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(ctx.out)
+	defer func() { _ = out.Flush() }()
+
+	width, height := lg.Width+2*layeredMargin, lg.Height+2*layeredMargin
+	fmt.Fprintf(out, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\">\n", width, height, width, height)
+	fmt.Fprintf(out, "<style>text { font-family: monospace; font-size: 11.5px; white-space: pre; }</style>\n")
+	fmt.Fprintf(out, "<rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n")
+	fmt.Fprintf(out, "<g transform=\"translate(%d %d)\">\n", layeredMargin, layeredMargin)
+	defer fmt.Fprintf(out, "</g>\n</svg>\n")
+
+	for _, c := range lg.Clusters {
+		if c.ID == "" || len(c.Nodes) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "<g><title>%s</title>\n", escapeXML(lg.clusterLabels[c.ID]))
+		fmt.Fprintf(out, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"#f6f6f6\" stroke=\"#bbb\"/>\n", c.X, c.Y, c.Width, c.Height)
+		fmt.Fprintf(out, "<text x=\"%.1f\" y=\"%.1f\">%s</text>\n", c.X+4, c.Y+13, escapeXML(lg.clusterLabels[c.ID]))
+		fmt.Fprintf(out, "</g>\n")
+	}
+
+	for _, e := range lg.Edges {
+		color := ctx.edgeColorOf(lg.nodes[e.From], lg.nodes[e.To])
+		fmt.Fprintf(out, "<g><title>%s -&gt; %s</title>\n", escapeXML(e.From.ID), escapeXML(e.To.ID))
+		fmt.Fprintf(out, "<path d=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"1.5\"/>\n", svgPath(e.Points), color)
+		tip, dir := arrowOf(e)
+		fmt.Fprintf(out, "<polygon points=\"%.1f,%.1f %.1f,%.1f %.1f,%.1f\" fill=\"%s\"/>\n",
+			tip.X, tip.Y, tip.X-7*dir, tip.Y-3.5, tip.X-7*dir, tip.Y+3.5, color)
+		fmt.Fprintf(out, "</g>\n")
+	}
+
+	for _, ln := range lg.Nodes {
+		n := lg.nodes[ln]
+		if ctx.docs != "" {
+			fmt.Fprintf(out, "<a href=\"%s\" target=\"_blank\">", escapeXML(ctx.docs+n.ID))
+		}
+		fmt.Fprintf(out, "<g><title>%s</title>\n", escapeXML(n.ID))
+		fmt.Fprintf(out, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"white\" stroke=\"%s\" stroke-width=\"2\"/>\n",
+			ln.X, ln.Y, ln.Width, ln.Height, ctx.colorOf(n))
+		for i, line := range lg.labels[ln] {
+			fmt.Fprintf(out, "<text x=\"%.1f\" y=\"%.1f\">%s</text>\n",
+				ln.X+layeredMetrics.padX,
+				ln.Y+layeredMetrics.padY+float64(i)*layeredMetrics.lineHeight+11,
+				escapeXML(line))
+		}
+		fmt.Fprintf(out, "</g>")
+		if ctx.docs != "" {
+			fmt.Fprintf(out, "</a>")
+		}
+		fmt.Fprintf(out, "\n")
+	}
+
+	return nil
+}
+
+// svgPath converts edge points to a path with horizontal tangents.
+func svgPath(points []layout.Point) string {
+	var d strings.Builder
+	fmt.Fprintf(&d, "M%.1f,%.1f", points[0].X, points[0].Y)
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if a.Y == b.Y {
+			fmt.Fprintf(&d, " L%.1f,%.1f", b.X, b.Y)
+			continue
+		}
+		mid := (a.X + b.X) / 2
+		fmt.Fprintf(&d, " C%.1f,%.1f %.1f,%.1f %.1f,%.1f", mid, a.Y, mid, b.Y, b.X, b.Y)
+	}
+	return d.String()
+}
+
+// arrowOf returns the arrow tip location and direction for an edge.
+func arrowOf(e *layout.Edge) (tip layout.Point, dir float64) {
+	if e.Reversed {
+		return e.Points[0], -1
+	}
+	return e.Points[len(e.Points)-1], 1
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (ctx *SVG) colorOf(p *pkggraph.Node) string {
+	if p.Color != "" {
+		return escapeXML(p.Color)
+	}
+	if ctx.nocolor {
+		return "black"
+	}
+
+	hash := sha256.Sum256([]byte(p.PkgPath))
+	hue := float64(uint(hash[0])<<8|uint(hash[1])) / 0xFFFF
+	return hslhex(hue, 0.9, 0.3)
+}
+
+func (ctx *SVG) edgeColorOf(src, dst *pkggraph.Node) string {
+	if color := src.EdgeColor[dst]; color != "" {
+		return escapeXML(color)
+	}
+	return ctx.colorOf(dst)
+}
@@ -0,0 +1,532 @@
+(function () {
+	"use strict";
+
+	const nodes = goda.nodes || [];
+
+	const canvas = document.getElementById("canvas");
+	const ctx = canvas.getContext("2d");
+	const panel = document.getElementById("panel");
+	const search = document.getElementById("search");
+	const matchesLabel = document.getElementById("matches");
+	const transitive = document.getElementById("transitive");
+
+	const font = "12px sans-serif";
+	const lineHeight = 14;
+	const layerGap = 80;
+	const rowGap = 8;
+
+	// collapsed contains module paths that are shown as a single unit.
+	const collapsed = new Set();
+
+	// units are the visible boxes, either a package or a collapsed module.
+	let units = [];
+	let unitOf = [];
+	let selectedKey = "";
+	let matches = [];
+	let matchIndex = -1;
+	let view = { x: 20, y: 20, scale: 1 };
+
+	function build() {
+		units = [];
+		unitOf = new Array(nodes.length);
+
+		const byKey = new Map();
+		nodes.forEach(function (n, i) {
+			const key = n.module && collapsed.has(n.module) ? "module:" + n.module : "node:" + i;
+			let u = byKey.get(key);
+			if (!u) {
+				u = {
+					key: key,
+					index: units.length,
+					module: n.module || "",
+					color: n.color || "#888",
+					nodes: [],
+					out: new Set(),
+					in: new Set(),
+					edgeColors: new Map(),
+				};
+				byKey.set(key, u);
+				units.push(u);
+			}
+			u.nodes.push(i);
+			unitOf[i] = u.index;
+		});
+
+		ctx.font = font;
+		units.forEach(function (u) {
+			if (u.key.startsWith("module:")) {
+				u.label = u.module + "\n" + u.nodes.length + " packages";
+			} else {
+				u.label = nodes[u.nodes[0]].label;
+			}
+			u.lines = u.label.split("\n").filter(function (line) { return line !== ""; });
+			let width = 0;
+			u.lines.forEach(function (line) {
+				width = Math.max(width, ctx.measureText(line).width);
+			});
+			u.w = width + 12;
+			u.h = u.lines.length * lineHeight + 8;
+		});
+
+		nodes.forEach(function (n, i) {
+			const a = unitOf[i];
+			(n.imports || []).forEach(function (k, j) {
+				const b = unitOf[k];
+				if (a !== b) {
+					units[a].out.add(b);
+					units[b].in.add(a);
+					if (n.importColors && n.importColors[j]) {
+						units[a].edgeColors.set(b, n.importColors[j]);
+					}
+				}
+			});
+		});
+
+		layout();
+		updateMatches();
+	}
+
+	function layout() {
+		// Assign layers by the longest path from a source, ignoring back edges.
+		const state = new Array(units.length).fill(0);
+		const layerOf = new Array(units.length).fill(0);
+		function visit(u) {
+			if (state[u] === 2) return layerOf[u];
+			if (state[u] === 1) return -1;
+			state[u] = 1;
+			let layer = 0;
+			units[u].in.forEach(function (p) {
+				const pl = visit(p);
+				if (pl >= 0) layer = Math.max(layer, pl + 1);
+			});
+			state[u] = 2;
+			layerOf[u] = layer;
+			return layer;
+		}
+		units.forEach(function (u) { visit(u.index); });
+
+		const layers = [];
+		units.forEach(function (u) {
+			u.layer = layerOf[u.index];
+			while (layers.length <= u.layer) layers.push([]);
+			layers[u.layer].push(u);
+		});
+
+		// Reduce crossings with barycenter sweeps.
+		function reindex() {
+			layers.forEach(function (layer) {
+				layer.forEach(function (u, i) { u.order = i; });
+			});
+		}
+		reindex();
+		for (let iter = 0; iter < 8; iter++) {
+			const down = iter % 2 === 0;
+			const start = down ? 1 : layers.length - 2;
+			const end = down ? layers.length : -1;
+			const step = down ? 1 : -1;
+			for (let l = start; l !== end; l += step) {
+				const layer = layers[l];
+				layer.forEach(function (u) {
+					let sum = 0, count = 0;
+					const neighbors = down ? u.in : u.out;
+					neighbors.forEach(function (v) {
+						const other = units[v];
+						if (down ? other.layer < l : other.layer > l) {
+							sum += other.order;
+							count++;
+						}
+					});
+					u.bary = count > 0 ? sum / count : u.order;
+				});
+				layer.sort(function (a, b) { return a.bary - b.bary || a.order - b.order; });
+				layer.forEach(function (u, i) { u.order = i; });
+			}
+		}
+
+		// Assign coordinates, centering each layer vertically.
+		const heights = layers.map(function (layer) {
+			return layer.reduce(function (sum, u) { return sum + u.h + rowGap; }, 0);
+		});
+		const maxHeight = Math.max.apply(null, heights.concat([0]));
+
+		let x = 0;
+		layers.forEach(function (layer, l) {
+			let width = 0;
+			let y = (maxHeight - heights[l]) / 2;
+			layer.forEach(function (u) {
+				u.x = x;
+				u.y = y;
+				y += u.h + rowGap;
+				width = Math.max(width, u.w);
+			});
+			x += width + layerGap;
+		});
+	}
+
+	function selectedUnit() {
+		for (const u of units) {
+			if (u.key === selectedKey) return u;
+		}
+		return null;
+	}
+
+	// reachable returns units reachable from u following dir ("in" or "out").
+	function reachable(u, dir, deep) {
+		const seen = new Set();
+		const stack = [u.index];
+		while (stack.length > 0) {
+			const next = units[stack.pop()];
+			next[dir].forEach(function (v) {
+				if (!seen.has(v) && v !== u.index) {
+					seen.add(v);
+					if (deep) stack.push(v);
+				}
+			});
+		}
+		return seen;
+	}
+
+	function draw() {
+		const dpr = window.devicePixelRatio || 1;
+		const width = canvas.clientWidth;
+		const height = canvas.clientHeight;
+		if (canvas.width !== width * dpr || canvas.height !== height * dpr) {
+			canvas.width = width * dpr;
+			canvas.height = height * dpr;
+		}
+
+		ctx.setTransform(dpr, 0, 0, dpr, 0, 0);
+		ctx.clearRect(0, 0, width, height);
+		ctx.translate(view.x, view.y);
+		ctx.scale(view.scale, view.scale);
+		ctx.font = font;
+		ctx.textBaseline = "top";
+
+		const left = -view.x / view.scale;
+		const top = -view.y / view.scale;
+		const right = left + width / view.scale;
+		const bottom = top + height / view.scale;
+		function visible(u) {
+			return u.x + u.w >= left && u.x <= right && u.y + u.h >= top && u.y <= bottom;
+		}
+
+		const selected = selectedUnit();
+		let imports = null, importers = null;
+		if (selected) {
+			imports = reachable(selected, "out", transitive.checked);
+			importers = reachable(selected, "in", transitive.checked);
+		}
+		function edgeActive(a, b) {
+			if (!selected) return true;
+			const fromSelf = a === selected.index || imports.has(a);
+			const toSelf = b === selected.index || importers.has(b);
+			return (fromSelf && imports.has(b)) || (toSelf && importers.has(a));
+		}
+		function unitActive(u) {
+			if (!selected) return true;
+			return u === selected || imports.has(u.index) || importers.has(u.index);
+		}
+
+		ctx.lineWidth = 1.5;
+		units.forEach(function (u) {
+			u.out.forEach(function (v) {
+				const w = units[v];
+				const minX = Math.min(u.x, w.x), maxX = Math.max(u.x + u.w, w.x + w.w);
+				const minY = Math.min(u.y, w.y), maxY = Math.max(u.y + u.h, w.y + w.h);
+				if (maxX < left || minX > right || maxY < top || minY > bottom) return;
+
+				const x1 = u.x + u.w, y1 = u.y + u.h / 2;
+				const x2 = w.x, y2 = w.y + w.h / 2;
+				const dx = Math.max(Math.abs(x2 - x1) / 2, 20);
+
+				ctx.globalAlpha = edgeActive(u.index, v) ? 0.8 : 0.06;
+				ctx.strokeStyle = u.edgeColors.get(v) || w.color;
+				ctx.beginPath();
+				ctx.moveTo(x1, y1);
+				ctx.bezierCurveTo(x1 + dx, y1, x2 - dx, y2, x2, y2);
+				ctx.stroke();
+			});
+		});
+
+		const matched = new Set(matches);
+		units.forEach(function (u) {
+			if (!visible(u)) return;
+
+			ctx.globalAlpha = unitActive(u) ? 1 : 0.15;
+			ctx.fillStyle = u === selected ? "#fff5c0" : "#fff";
+			ctx.strokeStyle = u.color;
+			ctx.lineWidth = u.key.startsWith("module:") ? 4 : 2;
+			ctx.fillRect(u.x, u.y, u.w, u.h);
+			ctx.strokeRect(u.x, u.y, u.w, u.h);
+			if (matched.has(u.index)) {
+				ctx.strokeStyle = "#e0a000";
+				ctx.lineWidth = 3;
+				ctx.strokeRect(u.x - 3, u.y - 3, u.w + 6, u.h + 6);
+			}
+
+			ctx.fillStyle = "#222";
+			u.lines.forEach(function (line, i) {
+				ctx.fillText(line, u.x + 6, u.y + 4 + i * lineHeight);
+			});
+		});
+		ctx.globalAlpha = 1;
+	}
+
+	function unitAt(clientX, clientY) {
+		const rect = canvas.getBoundingClientRect();
+		const x = (clientX - rect.left - view.x) / view.scale;
+		const y = (clientY - rect.top - view.y) / view.scale;
+		for (const u of units) {
+			if (x >= u.x && x <= u.x + u.w && y >= u.y && y <= u.y + u.h) {
+				return u;
+			}
+		}
+		return null;
+	}
+
+	function center(u) {
+		view.x = canvas.clientWidth / 2 - (u.x + u.w / 2) * view.scale;
+		view.y = canvas.clientHeight / 2 - (u.y + u.h / 2) * view.scale;
+	}
+
+	function fit() {
+		if (units.length === 0) return;
+		let maxX = 0, maxY = 0;
+		units.forEach(function (u) {
+			maxX = Math.max(maxX, u.x + u.w);
+			maxY = Math.max(maxY, u.y + u.h);
+		});
+		view.scale = Math.min(canvas.clientWidth / (maxX + 40), canvas.clientHeight / (maxY + 40), 1);
+		view.x = 20 * view.scale;
+		view.y = 20 * view.scale;
+	}
+
+	function escape(s) {
+		return String(s).replace(/[&<>"']/g, function (c) {
+			return { "&": "&amp;", "<": "&lt;", ">": "&gt;", "\"": "&quot;", "'": "&#39;" }[c];
+		});
+	}
+
+	function formatValue(key, value) {
+		if (/Size$/.test(key)) {
+			const units = ["B", "KB", "MB", "GB", "TB"];
+			let v = value, i = 0;
+			while (Math.abs(v) >= 1024 * 2 / 3 && i < units.length - 1) {
+				v /= 1024;
+				i++;
+			}
+			return i === 0 ? v + "B" : v.toFixed(1) + units[i];
+		}
+		return String(value);
+	}
+
+	// flatten converts nested stats into "Go.Lines" style keys.
+	function flatten(prefix, value, out) {
+		if (value !== null && typeof value === "object") {
+			Object.keys(value).forEach(function (key) {
+				flatten(prefix ? prefix + "." + key : key, value[key], out);
+			});
+		} else {
+			out[prefix] = value;
+		}
+		return out;
+	}
+
+	function sumStats(stats) {
+		const total = {};
+		stats.forEach(function (stat) {
+			const flat = flatten("", stat, {});
+			Object.keys(flat).forEach(function (key) {
+				total[key] = (total[key] || 0) + flat[key];
+			});
+		});
+		return total;
+	}
+
+	function statTable(columns) {
+		const names = Object.keys(columns);
+		const rows = Object.keys(columns[names[0]]);
+		let html = "<table><tr><th></th>";
+		names.forEach(function (name) { html += "<th>" + escape(name) + "</th>"; });
+		html += "</tr>";
+		rows.forEach(function (row) {
+			html += "<tr><td>" + escape(row) + "</td>";
+			names.forEach(function (name) {
+				html += "<td>" + escape(formatValue(row, columns[name][row] || 0)) + "</td>";
+			});
+			html += "</tr>";
+		});
+		return html + "</table>";
+	}
+
+	function unitList(title, set) {
+		const list = Array.from(set).map(function (i) { return units[i]; });
+		list.sort(function (a, b) { return a.lines[0] < b.lines[0] ? -1 : 1; });
+		let html = "<h3>" + escape(title) + " (" + list.length + ")</h3><ul>";
+		list.forEach(function (u) {
+			html += "<li><a class=\"node\" data-key=\"" + escape(u.key) + "\">" + escape(u.lines[0] || u.key) + "</a></li>";
+		});
+		return html + "</ul>";
+	}
+
+	function showPanel() {
+		const u = selectedUnit();
+		if (!u) {
+			panel.hidden = true;
+			return;
+		}
+
+		let html = "";
+		if (u.key.startsWith("module:")) {
+			html += "<h2>" + escape(u.module) + "</h2>";
+			html += "<button data-expand=\"" + escape(u.module) + "\">expand module</button>";
+			html += statTable({ Stat: sumStats(u.nodes.map(function (i) { return nodes[i].stat; })) });
+			html += "<h3>Packages</h3><ul>";
+			u.nodes.forEach(function (i) { html += "<li>" + escape(nodes[i].id) + "</li>"; });
+			html += "</ul>";
+		} else {
+			const n = nodes[u.nodes[0]];
+			html += "<h2>" + escape(n.id) + "</h2>";
+			if (n.docs) {
+				html += "<p><a href=\"" + escape(n.docs) + "\" target=\"_blank\">docs</a></p>";
+			}
+			if (n.module) {
+				html += "<p>module " + escape(n.module) + " ";
+				html += "<button data-collapse=\"" + escape(n.module) + "\">collapse module</button></p>";
+			}
+			html += statTable({
+				Stat: flatten("", n.stat, {}),
+				Up: flatten("", n.up, {}),
+				Down: flatten("", n.down, {}),
+			});
+		}
+		html += unitList("Imports", u.out);
+		html += unitList("Imported by", u.in);
+
+		panel.innerHTML = html;
+		panel.hidden = false;
+	}
+
+	function select(key, recenter) {
+		selectedKey = key;
+		const u = selectedUnit();
+		if (u && recenter) center(u);
+		showPanel();
+		draw();
+	}
+
+	function updateMatches() {
+		const query = search.value.trim().toLowerCase();
+		matches = [];
+		matchIndex = -1;
+		if (query !== "") {
+			units.forEach(function (u) {
+				if (u.label.toLowerCase().includes(query) || u.key.startsWith("node:") && nodes[u.nodes[0]].id.toLowerCase().includes(query)) {
+					matches.push(u.index);
+				}
+			});
+		}
+		matchesLabel.textContent = query === "" ? "" : matches.length + " matches";
+	}
+
+	function rebuild() {
+		const previous = selectedUnit();
+		const node = previous && previous.key.startsWith("node:") ? previous.nodes[0] : -1;
+		build();
+		if (node >= 0) {
+			selectedKey = units[unitOf[node]].key;
+		} else if (!selectedUnit()) {
+			selectedKey = "";
+		}
+		showPanel();
+		draw();
+	}
+
+	let drag = null;
+	canvas.addEventListener("mousedown", function (ev) {
+		drag = { x: ev.clientX, y: ev.clientY, vx: view.x, vy: view.y, moved: false };
+		canvas.classList.add("dragging");
+	});
+	window.addEventListener("mousemove", function (ev) {
+		if (!drag) return;
+		const dx = ev.clientX - drag.x, dy = ev.clientY - drag.y;
+		if (Math.abs(dx) + Math.abs(dy) > 3) drag.moved = true;
+		view.x = drag.vx + dx;
+		view.y = drag.vy + dy;
+		draw();
+	});
+	window.addEventListener("mouseup", function (ev) {
+		if (!drag) return;
+		const moved = drag.moved;
+		drag = null;
+		canvas.classList.remove("dragging");
+		if (!moved) {
+			const u = unitAt(ev.clientX, ev.clientY);
+			select(u ? u.key : "", false);
+		}
+	});
+	canvas.addEventListener("wheel", function (ev) {
+		ev.preventDefault();
+		const rect = canvas.getBoundingClientRect();
+		const mx = ev.clientX - rect.left, my = ev.clientY - rect.top;
+		const factor = Math.exp(-ev.deltaY * 0.0015);
+		const scale = Math.min(Math.max(view.scale * factor, 0.02), 8);
+		view.x = mx - (mx - view.x) * scale / view.scale;
+		view.y = my - (my - view.y) * scale / view.scale;
+		view.scale = scale;
+		draw();
+	}, { passive: false });
+
+	search.addEventListener("input", function () {
+		updateMatches();
+		draw();
+	});
+	search.addEventListener("keydown", function (ev) {
+		if (ev.key !== "Enter" || matches.length === 0) return;
+		matchIndex = (matchIndex + 1) % matches.length;
+		matchesLabel.textContent = (matchIndex + 1) + "/" + matches.length + " matches";
+		select(units[matches[matchIndex]].key, true);
+	});
+	transitive.addEventListener("change", draw);
+
+	panel.addEventListener("click", function (ev) {
+		const target = ev.target;
+		if (target.dataset.key) {
+			select(target.dataset.key, true);
+		} else if (target.dataset.collapse) {
+			collapsed.add(target.dataset.collapse);
+			rebuild();
+			selectedKey = "module:" + target.dataset.collapse;
+			showPanel();
+			draw();
+		} else if (target.dataset.expand) {
+			collapsed.delete(target.dataset.expand);
+			selectedKey = "";
+			rebuild();
+		}
+	});
+
+	document.getElementById("collapse-all").addEventListener("click", function () {
+		nodes.forEach(function (n) {
+			if (n.module) collapsed.add(n.module);
+		});
+		rebuild();
+		fit();
+		draw();
+	});
+	document.getElementById("expand-all").addEventListener("click", function () {
+		collapsed.clear();
+		rebuild();
+		fit();
+		draw();
+	});
+	document.getElementById("fit").addEventListener("click", function () {
+		fit();
+		draw();
+	});
+	window.addEventListener("resize", draw);
+
+	build();
+	fit();
+	draw();
+})();
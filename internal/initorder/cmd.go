@@ -0,0 +1,90 @@
+package initorder
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/templates"
+)
+
+type Command struct {
+	printStandard bool
+	onlyInits     bool
+	format        string
+}
+
+func (*Command) Name() string     { return "init-order" }
+func (*Command) Synopsis() string { return "Print package initialization order." }
+func (*Command) Usage() string {
+	return `init-order <expr>:
+	Print packages in the order they are initialized,
+	including all dependencies of the expression.
+
+	Decls.Init is the count of init functions and Decls.InitVar is the
+	count of package-level variables initialized with function calls or
+	composite literals.
+
+	See "help expr" for further information about expressions.
+	See "help format" for further information about formatting.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.onlyInits, "inits", false, "print only packages with init functions or initialized variables")
+	f.StringVar(&cmd.format, "f", "{{.ID}}\tinit:{{.Decls.Init}}\tvars:{{.Decls.InitVar}}", "formatting")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	t, err := templates.Parse(cmd.format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid format string: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.Calc(ctx, f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	all := pkgset.NewAll(result)
+	order := pkgset.InitOrder(all)
+	if !cmd.printStandard {
+		all = pkgset.Subtract(all, pkgset.Std())
+	}
+
+	graph := pkggraph.From(all)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	defer func() { _ = w.Flush() }()
+
+	for i, p := range order {
+		n, ok := graph.Packages[p.ID]
+		if !ok {
+			continue
+		}
+		if cmd.onlyInits && n.Decls.Init == 0 && n.Decls.InitVar == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "%d\t", i+1)
+		err := t.Execute(w, n)
+		fmt.Fprintln(w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "template error: %v\n", err)
+		}
+	}
+
+	return subcommands.ExitSuccess
+}
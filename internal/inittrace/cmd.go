@@ -0,0 +1,323 @@
+package inittrace
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/templates"
+)
+
+type Command struct {
+	printStandard bool
+	binary        string
+	modules       bool
+
+	noAlign bool
+	header  string
+	format  string
+}
+
+func (*Command) Name() string     { return "inittrace" }
+func (*Command) Synopsis() string { return "Measure package initialization cost." }
+func (*Command) Usage() string {
+	return `inittrace <expr> [-- args...]:
+	Build and run a main package with GODEBUG=inittrace=1 and
+	join the measured package initialization cost with the dependency graph.
+
+	The expression must contain a single main package, args are passed
+	to the program. Use -bin to run an already built binary instead.
+
+	The outermost context functions of the expression, e.g. in
+	"purego=1(./cmd/app:all)", are also used for building the binary.
+
+	Packages contain the following information in addition to "help format":
+
+	    type Node struct {
+	        Init     Cost // cost of initializing the package
+	        InitDown Cost // cost of the package and all of its dependencies
+	        InitCut  Cost // cost removed, when the package would be removed
+	    }
+
+	    type Cost struct {
+	        Clock  time.Duration
+	        Bytes  memory.Bytes
+	        Allocs int64
+	    }
+
+	See "help expr" for further information about expressions.
+	See "help format" for further information about formatting.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.StringVar(&cmd.binary, "bin", "", "use an already built binary")
+	f.BoolVar(&cmd.modules, "modules", false, "print cost per module")
+
+	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
+	f.StringVar(&cmd.header, "h", "", "header for the table\nautomatically derives from format, when empty, use \"-\" to skip")
+	f.StringVar(&cmd.format, "f", "{{.ID}}\t{{.Init.Clock}}\t{{.Init.Bytes}}\t{{.InitDown.Clock}}\t{{.InitCut.Clock}}", "info formatting")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	t, err := templates.Parse(cmd.format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid format string: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	expr, args := f.Args(), []string(nil)
+	if split := slices.Index(expr, "--"); split >= 0 {
+		expr, args = expr[:split], expr[split+1:]
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	root, err := pkgset.Parse(ctx, expr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	result, err := pkgset.Eval(ctx, root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	binary := cmd.binary
+	if binary == "" {
+		mains := pkgset.Main(result).IDs()
+		if len(mains) != 1 {
+			fmt.Fprintf(os.Stderr, "expected a single main package, got %v\n", mains)
+			return subcommands.ExitUsageError
+		}
+
+		dir, err := os.MkdirTemp("", "goda-inittrace-*")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create temporary directory: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+
+		binary = filepath.Join(dir, "main")
+		// Build with the same environment and tags as the expression.
+		config := pkgset.NewContext(ctx, pkgset.Settings(root)...).Config()
+		buildArgs := append([]string{"build"}, config.BuildFlags...)
+		buildArgs = append(buildArgs, "-o", binary, mains[0])
+		build := exec.CommandContext(ctx, "go", buildArgs...)
+		build.Env = config.Env
+		build.Stdout, build.Stderr = os.Stderr, os.Stderr
+		if err := build.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "build failed: %v\n", err)
+			return subcommands.ExitFailure
+		}
+	}
+
+	inits, err := Run(ctx, binary, args...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		if len(inits) == 0 {
+			return subcommands.ExitFailure
+		}
+	}
+
+	all := pkgset.NewAll(result)
+	if !cmd.printStandard {
+		all = pkgset.Subtract(all, pkgset.Std())
+	}
+	nodes := Attach(pkggraph.From(all), inits)
+
+	var w io.Writer = os.Stdout
+	if !cmd.noAlign {
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	}
+	defer func() {
+		if w, ok := w.(interface{ Flush() error }); ok {
+			_ = w.Flush()
+		}
+	}()
+
+	if cmd.modules {
+		fmt.Fprintln(w, "Module\tClock\tBytes\tAllocs")
+		for _, mod := range Modules(nodes) {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", mod.Path, mod.Clock, mod.Bytes, mod.Allocs)
+		}
+		return subcommands.ExitSuccess
+	}
+
+	if cmd.header != "-" {
+		if cmd.header == "" {
+			rx := regexp.MustCompile(`(\{\{\s*\.?|\s*\}\})`)
+			cmd.header = rx.ReplaceAllString(cmd.format, "")
+		}
+		fmt.Fprintln(w, cmd.header)
+	}
+	for _, node := range nodes {
+		err := t.Execute(w, node)
+		fmt.Fprintln(w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "template error: %v\n", err)
+		}
+	}
+
+	return subcommands.ExitSuccess
+}
+
+// Run runs binary with GODEBUG=inittrace=1 and parses the trace.
+func Run(ctx context.Context, binary string, args ...string) ([]Init, error) {
+	godebug := "inittrace=1"
+	if existing := os.Getenv("GODEBUG"); existing != "" {
+		godebug = existing + "," + godebug
+	}
+
+	var stderr bytes.Buffer
+	run := exec.CommandContext(ctx, binary, args...)
+	run.Env = append(os.Environ(), "GODEBUG="+godebug)
+	run.Stderr = &stderr
+	runErr := run.Run()
+
+	inits, err := Parse(&stderr)
+	if err != nil {
+		return inits, err
+	}
+	if len(inits) == 0 {
+		return nil, errors.Join(fmt.Errorf("no init trace found in the output of %v", binary), runErr)
+	}
+	if runErr != nil {
+		// The program may fail after initialization, e.g. due to missing arguments.
+		return inits, fmt.Errorf("%v exited with: %w", binary, runErr)
+	}
+	return inits, nil
+}
+
+// Node is a package with initialization cost.
+type Node struct {
+	*pkggraph.Node
+
+	Init     Cost
+	InitDown Cost
+	InitCut  Cost
+}
+
+// Attach joins inits with graph nodes and calculates the rollups.
+// The result is sorted by InitCut.Clock.
+func Attach(graph *pkggraph.Graph, inits []Init) []*Node {
+	nodes := map[*pkggraph.Node]*Node{}
+	for _, n := range graph.Sorted {
+		nodes[n] = &Node{Node: n}
+	}
+	for _, init := range inits {
+		if n, ok := graph.Packages[init.Package]; ok {
+			nodes[n].Init.Add(init.Cost)
+		} else if init.Package == "main" {
+			// The trace uses "main" for the main package.
+			for _, n := range graph.Sorted {
+				if n.Name == "main" {
+					nodes[n].Init.Add(init.Cost)
+				}
+			}
+		}
+	}
+
+	indegree := map[*pkggraph.Node]int{}
+	reset := func() {
+		clear(indegree)
+		for _, n := range graph.Sorted {
+			for _, dep := range n.ImportsNodes {
+				indegree[dep]++
+			}
+		}
+	}
+
+	var erase func(n *pkggraph.Node) Cost
+	erase = func(n *pkggraph.Node) Cost {
+		cut := nodes[n].Init
+		for _, dep := range n.ImportsNodes {
+			indegree[dep]--
+			if indegree[dep] == 0 {
+				cut.Add(erase(dep))
+			}
+		}
+		return cut
+	}
+
+	var result []*Node
+	for _, n := range graph.Sorted {
+		node := nodes[n]
+
+		visited := map[*pkggraph.Node]bool{}
+		var visit func(n *pkggraph.Node)
+		visit = func(n *pkggraph.Node) {
+			if visited[n] {
+				return
+			}
+			visited[n] = true
+			node.InitDown.Add(nodes[n].Init)
+			for _, dep := range n.ImportsNodes {
+				visit(dep)
+			}
+		}
+		visit(n)
+
+		reset()
+		node.InitCut = erase(n)
+
+		result = append(result, node)
+	}
+
+	slices.SortStableFunc(result, func(a, b *Node) int {
+		return cmp.Compare(b.InitCut.Clock, a.InitCut.Clock)
+	})
+	return result
+}
+
+// Module is the initialization cost of a module.
+type Module struct {
+	Path string
+	Cost
+}
+
+// Modules sums the cost of packages per module, sorted by Clock.
+func Modules(nodes []*Node) []*Module {
+	byPath := map[string]*Module{}
+	var modules []*Module
+	for _, n := range nodes {
+		path := "std"
+		if n.Module != nil {
+			path = n.Module.Path
+		}
+		mod, ok := byPath[path]
+		if !ok {
+			mod = &Module{Path: path}
+			byPath[path] = mod
+			modules = append(modules, mod)
+		}
+		mod.Add(n.Init)
+	}
+
+	slices.SortStableFunc(modules, func(a, b *Module) int {
+		if r := cmp.Compare(b.Clock, a.Clock); r != 0 {
+			return r
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return modules
+}
@@ -0,0 +1,79 @@
+package inittrace
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/loov/goda/internal/memory"
+)
+
+// Cost is the cost of initializing packages.
+type Cost struct {
+	Clock  time.Duration
+	Bytes  memory.Bytes
+	Allocs int64
+}
+
+func (c *Cost) Add(b Cost) {
+	c.Clock += b.Clock
+	c.Bytes += b.Bytes
+	c.Allocs += b.Allocs
+}
+
+// Init is a single package initialization from the trace.
+type Init struct {
+	Package string
+	// At is the start time relative to the program start.
+	At time.Duration
+	Cost
+}
+
+// rxInit matches lines printed by GODEBUG=inittrace=1, e.g.
+//
+//	init internal/bytealg @0.008 ms, 0 ms clock, 0 bytes, 0 allocs
+var rxInit = regexp.MustCompile(`^init (\S+) @([0-9.]+) ms, ([0-9.]+) ms clock, (\d+) bytes, (\d+) allocs$`)
+
+// Parse parses the output of GODEBUG=inittrace=1, other lines are ignored.
+func Parse(r io.Reader) ([]Init, error) {
+	var inits []Init
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		match := rxInit.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+
+		at, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			return inits, err
+		}
+		clock, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			return inits, err
+		}
+		bytes, err := strconv.ParseInt(match[4], 10, 64)
+		if err != nil {
+			return inits, err
+		}
+		allocs, err := strconv.ParseInt(match[5], 10, 64)
+		if err != nil {
+			return inits, err
+		}
+
+		inits = append(inits, Init{
+			Package: match[1],
+			At:      time.Duration(at * float64(time.Millisecond)),
+			Cost: Cost{
+				Clock:  time.Duration(clock * float64(time.Millisecond)),
+				Bytes:  memory.Bytes(bytes),
+				Allocs: allocs,
+			},
+		})
+	}
+
+	return inits, scanner.Err()
+}
@@ -0,0 +1,34 @@
+package inittrace
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	trace := strings.Join([]string{
+		"init internal/bytealg @0.008 ms, 0 ms clock, 0 bytes, 0 allocs",
+		"program output",
+		"init runtime @0.059 ms, 0.026 ms clock, 0 bytes, 0 allocs",
+		"init github.com/example/big @12 ms, 10 ms clock, 5300 bytes, 42 allocs",
+	}, "\n")
+
+	inits, err := Parse(strings.NewReader(trace))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inits) != 3 {
+		t.Fatalf("expected 3 inits, got %d", len(inits))
+	}
+
+	runtime := inits[1]
+	if runtime.Package != "runtime" || runtime.At != 59*time.Microsecond || runtime.Clock != 26*time.Microsecond {
+		t.Errorf("invalid runtime init %+v", runtime)
+	}
+
+	big := inits[2]
+	if big.Package != "github.com/example/big" || big.Clock != 10*time.Millisecond || big.Bytes != 5300 || big.Allocs != 42 {
+		t.Errorf("invalid big init %+v", big)
+	}
+}
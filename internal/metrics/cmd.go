@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/templates"
+)
+
+type Command struct {
+	printStandard bool
+	sort          string
+	reverse       bool
+
+	noAlign bool
+	header  string
+	format  string
+}
+
+func (*Command) Name() string     { return "metrics" }
+func (*Command) Synopsis() string { return "Print package coupling metrics." }
+func (*Command) Usage() string {
+	return `metrics <expr>:
+	Print package coupling metrics for packages in expr:
+
+		Ca  afferent coupling, count of importers within expr
+		Ce  efferent coupling, count of imports within expr
+		I   instability, Ce / (Ca + Ce)
+		A   abstractness, interfaces / all types
+		D   distance from the main sequence, |A + I - 1|
+
+	Packages with D close to 1 are either concrete and heavily
+	depended on or abstract and unused.
+
+	The output can be sorted with -sort id, ca, ce, i, a or d.
+	Numeric columns are sorted in descending order.
+
+	See "help expr" for further information about expressions.
+	See "help format" for further information about formatting.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.StringVar(&cmd.sort, "sort", "id", "sort by id, ca, ce, i, a or d")
+	f.BoolVar(&cmd.reverse, "reverse", false, "reverse the sort order")
+
+	f.BoolVar(&cmd.noAlign, "noalign", false, "disable aligning tabs")
+	f.StringVar(&cmd.header, "h", "", "header for the table\nautomatically derives from format, when empty, use \"-\" to skip")
+	f.StringVar(&cmd.format, "f", `{{.ID}}	{{.Metrics.Ca}}	{{.Metrics.Ce}}	{{printf "%.2f" .Metrics.Instability}}	{{printf "%.2f" .Metrics.Abstractness}}	{{printf "%.2f" .Metrics.Distance}}`, "formatting")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	t, err := templates.Parse(cmd.format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid format string: %v\n", err)
+		return subcommands.ExitFailure
+	}
+
+	compare, ok := sortBy[strings.ToLower(cmd.sort)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown sort %q\n", cmd.sort)
+		return subcommands.ExitUsageError
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.Calc(ctx, f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	graph := pkggraph.From(result)
+
+	nodes := slices.Clone(graph.Sorted)
+	slices.SortStableFunc(nodes, compare)
+	if cmd.reverse {
+		slices.Reverse(nodes)
+	}
+
+	var w io.Writer = os.Stdout
+	if !cmd.noAlign {
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	}
+	if cmd.header != "-" {
+		if cmd.header == "" {
+			if cmd.format == f.Lookup("f").DefValue {
+				cmd.header = "ID\tCa\tCe\tI\tA\tD"
+			} else {
+				rx := regexp.MustCompile(`(\{\{\s*\.?|\s*\}\})`)
+				cmd.header = rx.ReplaceAllString(cmd.format, "")
+			}
+		}
+		fmt.Fprintln(w, cmd.header)
+	}
+	for _, n := range nodes {
+		err := t.Execute(w, n)
+		fmt.Fprintln(w)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "template error: %v\n", err)
+		}
+	}
+	if w, ok := w.(interface{ Flush() error }); ok {
+		w.Flush()
+	}
+
+	return subcommands.ExitSuccess
+}
+
+// sortBy contains comparisons for sorting nodes, numeric values
+// are sorted in descending order.
+var sortBy = map[string]func(a, b *pkggraph.Node) int{
+	"id": func(a, b *pkggraph.Node) int { return strings.Compare(a.ID, b.ID) },
+	"ca": func(a, b *pkggraph.Node) int { return b.Metrics.Ca - a.Metrics.Ca },
+	"ce": func(a, b *pkggraph.Node) int { return b.Metrics.Ce - a.Metrics.Ce },
+	"i":  func(a, b *pkggraph.Node) int { return compareFloat(b.Metrics.Instability, a.Metrics.Instability) },
+	"a":  func(a, b *pkggraph.Node) int { return compareFloat(b.Metrics.Abstractness, a.Metrics.Abstractness) },
+	"d":  func(a, b *pkggraph.Node) int { return compareFloat(b.Metrics.Distance, a.Metrics.Distance) },
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,163 @@
+package modinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/tools/go/packages"
+)
+
+// Module contains information about a module from its go.mod.
+type Module struct {
+	Path    string
+	Version string
+
+	// Go is the go directive.
+	Go string
+	// Toolchain is the toolchain directive.
+	Toolchain string
+
+	// Deprecated is the deprecation message of the module.
+	Deprecated string `json:",omitempty"`
+	// Retracted contains rationales of retractions covering Version.
+	Retracted []string `json:",omitempty"`
+
+	Replaced     bool
+	Pseudo       bool
+	Incompatible bool
+}
+
+// IsRetracted returns whether Version has been retracted.
+func (mod *Module) IsRetracted() bool { return len(mod.Retracted) > 0 }
+
+var cache struct {
+	sync.Mutex
+	modules map[string]*Module
+}
+
+// Of returns information about mod, nil mod returns an empty Module.
+//
+// Deprecations and retractions are read from the latest version of the
+// module in the module cache, hence they are only reported for versions
+// that have been downloaded. Missing files are ignored.
+func Of(mod *packages.Module) *Module {
+	if mod == nil {
+		return &Module{}
+	}
+
+	key := mod.Path + "@" + mod.Version
+	if mod.Replace != nil {
+		key += "=>" + mod.Replace.Path + "@" + mod.Replace.Version
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if info, ok := cache.modules[key]; ok {
+		return info
+	}
+	if cache.modules == nil {
+		cache.modules = map[string]*Module{}
+	}
+
+	info := load(mod)
+	cache.modules[key] = info
+	return info
+}
+
+func load(mod *packages.Module) *Module {
+	info := &Module{
+		Path:         mod.Path,
+		Version:      mod.Version,
+		Replaced:     mod.Replace != nil,
+		Pseudo:       module.IsPseudoVersion(mod.Version),
+		Incompatible: strings.HasSuffix(mod.Version, "+incompatible"),
+	}
+
+	gomod := mod.GoMod
+	if mod.Replace != nil && mod.Replace.GoMod != "" {
+		gomod = mod.Replace.GoMod
+	}
+	file := parse(gomod)
+	if file == nil {
+		return info
+	}
+	if file.Go != nil {
+		info.Go = file.Go.Version
+	}
+	if file.Toolchain != nil {
+		info.Toolchain = file.Toolchain.Name
+	}
+
+	// Deprecations and retractions apply from the latest version.
+	if mod.Replace == nil && !mod.Main && mod.Version != "" {
+		if latest := parse(latestInCache(gomod, mod.Version)); latest != nil {
+			file = latest
+		}
+	}
+	if file.Module != nil {
+		info.Deprecated = file.Module.Deprecated
+	}
+	for _, retract := range file.Retract {
+		if semver.Compare(retract.Low, mod.Version) <= 0 && semver.Compare(mod.Version, retract.High) <= 0 {
+			rationale := retract.Rationale
+			if rationale == "" {
+				rationale = "retracted by module author"
+			}
+			info.Retracted = append(info.Retracted, rationale)
+		}
+	}
+
+	return info
+}
+
+// latestInCache finds the latest version of the go.mod in the module cache
+// directory, where gomod is "<cache>/cache/download/<path>/@v/<version>.mod".
+func latestInCache(gomod, version string) string {
+	dir := filepath.Dir(gomod)
+	if filepath.Base(dir) != "@v" {
+		return ""
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	latest := version
+	for _, entry := range entries {
+		v, ok := strings.CutSuffix(entry.Name(), ".mod")
+		if !ok || !semver.IsValid(v) || semver.Prerelease(v) != "" && semver.Prerelease(latest) == "" {
+			continue
+		}
+		if semver.Compare(v, latest) > 0 {
+			latest = v
+		}
+	}
+	if latest == version {
+		return ""
+	}
+	return filepath.Join(dir, latest+".mod")
+}
+
+func parse(gomod string) *modfile.File {
+	if gomod == "" {
+		return nil
+	}
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return nil
+	}
+	file, err := modfile.Parse(gomod, data, nil)
+	if err != nil {
+		file, err = modfile.ParseLax(gomod, data, nil)
+		if err != nil {
+			return nil
+		}
+	}
+	return file
+}
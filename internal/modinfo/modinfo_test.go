@@ -0,0 +1,51 @@
+package modinfo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestOf(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "example.com", "dep", "@v")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(version, content string) string {
+		file := filepath.Join(dir, version+".mod")
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+
+	selected := write("v1.0.0", "module example.com/dep\n\ngo 1.21\n\ntoolchain go1.22.1\n")
+	write("v1.2.0", "// Deprecated: use example.com/dep/v2\nmodule example.com/dep\n\ngo 1.22\n\nretract [v1.0.0, v1.1.0] // broken\n")
+	write("v1.3.0-rc.1", "module example.com/dep\n\ngo 1.23\n")
+
+	info := Of(&packages.Module{Path: "example.com/dep", Version: "v1.0.0", GoMod: selected})
+	if info.Go != "1.21" || info.Toolchain != "go1.22.1" {
+		t.Errorf("expected directives from the selected version, got go %q toolchain %q", info.Go, info.Toolchain)
+	}
+	if info.Deprecated != "use example.com/dep/v2" {
+		t.Errorf("got deprecation %q", info.Deprecated)
+	}
+	if !slices.Equal(info.Retracted, []string{"broken"}) {
+		t.Errorf("got retracted %q", info.Retracted)
+	}
+	if info.Pseudo || info.Incompatible || info.Replaced {
+		t.Errorf("unexpected version info %+v", info)
+	}
+
+	pseudo := Of(&packages.Module{Path: "example.com/other", Version: "v0.0.0-20190513183733-4bf6d317e70e"})
+	if !pseudo.Pseudo {
+		t.Errorf("expected pseudo-version")
+	}
+	incompatible := Of(&packages.Module{Path: "example.com/old", Version: "v3.0.0+incompatible"})
+	if !incompatible.Incompatible {
+		t.Errorf("expected +incompatible")
+	}
+}
@@ -0,0 +1,95 @@
+package partition
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgset"
+)
+
+type Command struct {
+	printStandard bool
+	k             int
+	weight        string
+}
+
+func (*Command) Name() string     { return "partition" }
+func (*Command) Synopsis() string { return "Propose groups of packages." }
+func (*Command) Usage() string {
+	return `partition <expr>:
+	Divide packages into groups with few imports between them,
+	e.g. for splitting a module into several modules.
+
+	Groups are found with greedy modularity maximization, where
+	imports are weighted by the size of the imported package.
+	With -k 0 the number of groups is chosen automatically.
+
+	Prints the groups, the imports between groups and the
+	resulting graph between groups.
+
+	To color the graph by the proposed groups use:
+
+		goda graph -partition 4 ./... | dot -Tsvg -o graph.svg
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.IntVar(&cmd.k, "k", 0, "number of groups, 0 for automatic")
+	f.StringVar(&cmd.weight, "weight", "lines", "weight of imports (edges, lines, size)")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	weight, ok := Weights[cmd.weight]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown weight %q\n", cmd.weight)
+		return subcommands.ExitUsageError
+	}
+
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	result, err := pkgset.Calc(ctx, f.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	p := Detect(pkggraph.From(result), cmd.k, weight)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer func() { _ = w.Flush() }()
+
+	for _, g := range p.Groups {
+		fmt.Fprintf(w, "%d. %v\t%d packages\t%d lines\n", g.ID, g.Name, len(g.Nodes), g.Stat.Go.Lines)
+		for _, n := range g.Nodes {
+			fmt.Fprintf(w, "  %v\n", n.ID)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "cut edges: %d\n", len(p.Cut))
+	for _, edge := range p.Cut {
+		fmt.Fprintf(w, "  %v -> %v\t%d -> %d\n", edge.From.ID, edge.To.ID, p.GroupOf[edge.From].ID, p.GroupOf[edge.To].ID)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "group graph:")
+	for _, edge := range p.GroupEdges {
+		fmt.Fprintf(w, "  %d. %v -> %d. %v\t%d imports\n", edge.From.ID, edge.From.Name, edge.To.ID, edge.To.Name, edge.Count)
+	}
+
+	return subcommands.ExitSuccess
+}
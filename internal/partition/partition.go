@@ -0,0 +1,211 @@
+package partition
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/stat"
+	"github.com/loov/goda/internal/visibility"
+)
+
+// Weight returns the weight of an import of n.
+type Weight func(n *pkggraph.Node) float64
+
+// Weights contains supported weights for imports.
+var Weights = map[string]Weight{
+	"edges": func(n *pkggraph.Node) float64 { return 1 },
+	"lines": func(n *pkggraph.Node) float64 { return float64(n.Stat.Go.Lines) },
+	"size":  func(n *pkggraph.Node) float64 { return float64(n.Stat.Go.Size) },
+}
+
+// Group is a set of packages in the partition.
+type Group struct {
+	ID   int
+	Name string
+
+	Nodes []*pkggraph.Node
+	Stat  stat.Stat
+}
+
+// Edge is an import between different groups.
+type Edge struct {
+	From, To *pkggraph.Node
+}
+
+// GroupEdge summarizes imports between groups.
+type GroupEdge struct {
+	From, To *Group
+	Count    int
+}
+
+// Partition is a division of packages into groups.
+type Partition struct {
+	Groups  []*Group
+	GroupOf map[*pkggraph.Node]*Group
+
+	// Cut contains imports between groups.
+	Cut []Edge
+	// GroupEdges contains the graph between groups.
+	GroupEdges []GroupEdge
+}
+
+// Detect divides nodes in graph into k groups using greedy modularity
+// maximization (Clauset-Newman-Moore), where edges are undirected and
+// weighted by the imported node.
+//
+// When k <= 0, merging stops when modularity no longer improves.
+func Detect(graph *pkggraph.Graph, k int, weight Weight) *Partition {
+	nodes := graph.Sorted
+	index := make(map[*pkggraph.Node]int, len(nodes))
+	for i, n := range nodes {
+		index[n] = i
+	}
+
+	members := make(map[int][]*pkggraph.Node, len(nodes))
+	links := make(map[int]map[int]float64, len(nodes))
+	degree := make(map[int]float64, len(nodes))
+	for i, n := range nodes {
+		members[i] = []*pkggraph.Node{n}
+		links[i] = map[int]float64{}
+	}
+
+	total := 0.0
+	for _, n := range nodes {
+		for _, dep := range n.ImportsNodes {
+			i, j := index[n], index[dep]
+			if i == j {
+				continue
+			}
+			w := max(weight(dep), 1)
+			links[i][j] += w
+			links[j][i] += w
+			degree[i] += w
+			degree[j] += w
+			total += w
+		}
+	}
+
+	for len(members) > max(k, 1) {
+		ids := sortedKeys(members)
+
+		// Find the merge with the largest modularity gain.
+		bestI, bestJ, bestGain := -1, -1, 0.0
+		for _, i := range ids {
+			for j, w := range links[i] {
+				if j <= i {
+					continue
+				}
+				gain := w/total - degree[i]*degree[j]/(2*total*total)
+				if bestI < 0 || gain > bestGain || gain == bestGain && (i < bestI || i == bestI && j < bestJ) {
+					bestI, bestJ, bestGain = i, j, gain
+				}
+			}
+		}
+
+		if bestI < 0 || bestGain <= 0 {
+			if k <= 0 {
+				break
+			}
+			if bestI < 0 {
+				// There are no imports between groups,
+				// hence merge the two smallest groups.
+				slices.SortStableFunc(ids, func(a, b int) int {
+					return compareFloat(degree[a]+float64(len(members[a])), degree[b]+float64(len(members[b])))
+				})
+				bestI, bestJ = min(ids[0], ids[1]), max(ids[0], ids[1])
+			}
+		}
+
+		// Merge bestJ into bestI.
+		members[bestI] = append(members[bestI], members[bestJ]...)
+		for x, w := range links[bestJ] {
+			delete(links[x], bestJ)
+			if x == bestI {
+				continue
+			}
+			links[bestI][x] += w
+			links[x][bestI] += w
+		}
+		degree[bestI] += degree[bestJ]
+		delete(members, bestJ)
+		delete(links, bestJ)
+		delete(degree, bestJ)
+	}
+
+	return newPartition(nodes, members)
+}
+
+func newPartition(nodes []*pkggraph.Node, members map[int][]*pkggraph.Node) *Partition {
+	p := &Partition{GroupOf: map[*pkggraph.Node]*Group{}}
+	for _, group := range members {
+		pkggraph.SortNodes(group)
+		g := &Group{Nodes: group}
+		paths := make([]string, 0, len(group))
+		for _, n := range group {
+			g.Stat.Add(n.Stat)
+			paths = append(paths, n.PkgPath)
+		}
+		g.Name = visibility.CommonParent(paths)
+		p.Groups = append(p.Groups, g)
+	}
+
+	slices.SortFunc(p.Groups, func(a, b *Group) int {
+		if len(a.Nodes) != len(b.Nodes) {
+			return len(b.Nodes) - len(a.Nodes)
+		}
+		return strings.Compare(a.Nodes[0].ID, b.Nodes[0].ID)
+	})
+	for i, g := range p.Groups {
+		g.ID = i + 1
+		if g.Name == "" {
+			g.Name = fmt.Sprintf("group %d", g.ID)
+		}
+		for _, n := range g.Nodes {
+			p.GroupOf[n] = g
+		}
+	}
+
+	groupEdges := map[[2]*Group]int{}
+	for _, n := range nodes {
+		for _, dep := range n.ImportsNodes {
+			from, to := p.GroupOf[n], p.GroupOf[dep]
+			if from == to {
+				continue
+			}
+			p.Cut = append(p.Cut, Edge{From: n, To: dep})
+			groupEdges[[2]*Group{from, to}]++
+		}
+	}
+	for key, count := range groupEdges {
+		p.GroupEdges = append(p.GroupEdges, GroupEdge{From: key[0], To: key[1], Count: count})
+	}
+	slices.SortFunc(p.GroupEdges, func(a, b GroupEdge) int {
+		if a.From.ID != b.From.ID {
+			return a.From.ID - b.From.ID
+		}
+		return a.To.ID - b.To.ID
+	})
+
+	return p
+}
+
+func sortedKeys[V any](m map[int]V) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+package partition
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkggraph"
+)
+
+func TestDetect(t *testing.T) {
+	g := &pkggraph.Graph{Packages: map[string]*pkggraph.Node{}}
+	nodes := map[string]*pkggraph.Node{}
+	for _, id := range []string{"a/1", "a/2", "a/3", "b/1", "b/2", "b/3"} {
+		n := &pkggraph.Node{Package: &packages.Package{ID: id, PkgPath: id}}
+		g.AddNode(n)
+		g.Sorted = append(g.Sorted, n)
+		nodes[id] = n
+	}
+	imports := func(from string, to ...string) {
+		for _, id := range to {
+			nodes[from].ImportsNodes = append(nodes[from].ImportsNodes, nodes[id])
+		}
+	}
+	imports("a/1", "a/2", "a/3")
+	imports("a/2", "a/3")
+	imports("b/1", "b/2", "b/3")
+	imports("b/2", "b/3")
+	imports("a/3", "b/1")
+
+	for _, k := range []int{0, 2} {
+		p := Detect(g, k, Weights["edges"])
+		if len(p.Groups) != 2 {
+			t.Fatalf("k=%d: expected 2 groups, got %d", k, len(p.Groups))
+		}
+		if p.Groups[0].Name != "a" || p.Groups[1].Name != "b" {
+			t.Errorf("k=%d: expected groups a and b, got %q and %q", k, p.Groups[0].Name, p.Groups[1].Name)
+		}
+		if len(p.Cut) != 1 || p.Cut[0].From != nodes["a/3"] || p.Cut[0].To != nodes["b/1"] {
+			t.Errorf("k=%d: expected a/3 -> b/1 to be cut, got %v", k, p.Cut)
+		}
+	}
+
+	if p := Detect(g, 1, Weights["edges"]); len(p.Groups) != 1 || len(p.Cut) != 0 {
+		t.Errorf("k=1: expected a single group without cut edges")
+	}
+	if p := Detect(g, 3, Weights["edges"]); len(p.Groups) != 3 {
+		t.Errorf("k=3: expected 3 groups, got %d", len(p.Groups))
+	}
+}
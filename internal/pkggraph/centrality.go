@@ -0,0 +1,177 @@
+package pkggraph
+
+import "math"
+
+// centrality describes importance of a node in the graph.
+//
+// It's calculated for the whole graph when first needed, since
+// betweenness is too expensive to calculate for every command.
+// Changing the imports after that requires calling Graph.Link.
+type centrality struct {
+	pageRank    float64
+	betweenness float64
+	level       int
+	depth       int
+}
+
+// PageRank returns the PageRank of the node, where rank flows
+// from importers to the imported packages.
+func (n *Node) PageRank() float64 { return n.centrality().pageRank }
+
+// Betweenness returns how many shortest import paths between
+// other packages go through the node.
+func (n *Node) Betweenness() float64 { return n.centrality().betweenness }
+
+// Level returns the longest import path from the node to a package
+// without imports.
+func (n *Node) Level() int { return n.centrality().level }
+
+// Depth returns the longest import path from a package,
+// which is not imported, to the node.
+func (n *Node) Depth() int { return n.centrality().depth }
+
+func (n *Node) centrality() centrality {
+	if n.Graph == nil {
+		return centrality{}
+	}
+	n.Graph.centralityOnce.Do(n.Graph.calculateCentrality)
+	return n.Graph.centrality[n]
+}
+
+func (g *Graph) calculateCentrality() {
+	g.centrality = make(map[*Node]centrality, len(g.Sorted))
+
+	importedBy := map[*Node][]*Node{}
+	for _, n := range g.Sorted {
+		for _, dep := range n.ImportsNodes {
+			importedBy[dep] = append(importedBy[dep], n)
+		}
+	}
+
+	pageRank := g.pageRank()
+	betweenness := g.betweenness()
+	level := longestPaths(g.Sorted, func(n *Node) []*Node { return n.ImportsNodes })
+	depth := longestPaths(g.Sorted, func(n *Node) []*Node { return importedBy[n] })
+
+	for _, n := range g.Sorted {
+		g.centrality[n] = centrality{
+			pageRank:    pageRank[n],
+			betweenness: betweenness[n],
+			level:       level[n],
+			depth:       depth[n],
+		}
+	}
+}
+
+func (g *Graph) pageRank() map[*Node]float64 {
+	const (
+		damping   = 0.85
+		maxRounds = 100
+		epsilon   = 1e-10
+	)
+
+	count := float64(len(g.Sorted))
+	rank := make(map[*Node]float64, len(g.Sorted))
+	for _, n := range g.Sorted {
+		rank[n] = 1 / count
+	}
+
+	for range maxRounds {
+		// Nodes without imports distribute their rank evenly.
+		dangling := 0.0
+		for _, n := range g.Sorted {
+			if len(n.ImportsNodes) == 0 {
+				dangling += rank[n]
+			}
+		}
+
+		next := make(map[*Node]float64, len(g.Sorted))
+		for _, n := range g.Sorted {
+			next[n] += (1-damping)/count + damping*dangling/count
+			for _, dep := range n.ImportsNodes {
+				next[dep] += damping * rank[n] / float64(len(n.ImportsNodes))
+			}
+		}
+
+		change := 0.0
+		for _, n := range g.Sorted {
+			change += math.Abs(next[n] - rank[n])
+		}
+		rank = next
+		if change < epsilon {
+			break
+		}
+	}
+
+	return rank
+}
+
+// betweenness uses Brandes' algorithm for unweighted directed graphs.
+func (g *Graph) betweenness() map[*Node]float64 {
+	result := make(map[*Node]float64, len(g.Sorted))
+	for _, source := range g.Sorted {
+		var stack []*Node
+		preds := map[*Node][]*Node{}
+		paths := map[*Node]float64{source: 1}
+		dist := map[*Node]int{source: 0}
+
+		queue := []*Node{source}
+		for len(queue) > 0 {
+			n := queue[0]
+			queue = queue[1:]
+			stack = append(stack, n)
+			for _, dep := range n.ImportsNodes {
+				if _, ok := dist[dep]; !ok {
+					dist[dep] = dist[n] + 1
+					queue = append(queue, dep)
+				}
+				if dist[dep] == dist[n]+1 {
+					paths[dep] += paths[n]
+					preds[dep] = append(preds[dep], n)
+				}
+			}
+		}
+
+		delta := map[*Node]float64{}
+		for i := len(stack) - 1; i >= 0; i-- {
+			n := stack[i]
+			for _, pred := range preds[n] {
+				delta[pred] += paths[pred] / paths[n] * (1 + delta[n])
+			}
+			if n != source {
+				result[n] += delta[n]
+			}
+		}
+	}
+	return result
+}
+
+// longestPaths calculates the longest path following next for every node.
+// Edges closing a cycle are ignored.
+func longestPaths(nodes []*Node, next func(*Node) []*Node) map[*Node]int {
+	length := make(map[*Node]int, len(nodes))
+	visiting := map[*Node]bool{}
+
+	var visit func(n *Node) int
+	visit = func(n *Node) int {
+		if l, ok := length[n]; ok {
+			return l
+		}
+		if visiting[n] {
+			return -1
+		}
+		visiting[n] = true
+		longest := 0
+		for _, dep := range next(n) {
+			longest = max(longest, visit(dep)+1)
+		}
+		visiting[n] = false
+		length[n] = longest
+		return longest
+	}
+
+	for _, n := range nodes {
+		visit(n)
+	}
+	return length
+}
@@ -0,0 +1,79 @@
+package pkggraph
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestCentrality(t *testing.T) {
+	// a -> b -> d
+	// a -> c -> d
+	g := &Graph{Packages: map[string]*Node{}}
+	node := func(id string, imports ...*Node) *Node {
+		n := &Node{Package: &packages.Package{ID: id}, ImportsNodes: imports}
+		g.AddNode(n)
+		g.Sorted = append(g.Sorted, n)
+		return n
+	}
+	d := node("d")
+	b := node("b", d)
+	c := node("c", d)
+	a := node("a", b, c)
+
+	for _, test := range []struct {
+		node         *Node
+		level, depth int
+		betweenness  float64
+	}{
+		{a, 2, 0, 0},
+		{b, 1, 1, 0.5},
+		{c, 1, 1, 0.5},
+		{d, 0, 2, 0},
+	} {
+		if got := test.node.Level(); got != test.level {
+			t.Errorf("%v: level %v, expected %v", test.node.ID, got, test.level)
+		}
+		if got := test.node.Depth(); got != test.depth {
+			t.Errorf("%v: depth %v, expected %v", test.node.ID, got, test.depth)
+		}
+		if got := test.node.Betweenness(); got != test.betweenness {
+			t.Errorf("%v: betweenness %v, expected %v", test.node.ID, got, test.betweenness)
+		}
+	}
+
+	if !(d.PageRank() > b.PageRank() && b.PageRank() > a.PageRank()) {
+		t.Errorf("invalid page rank order: a=%v b=%v d=%v", a.PageRank(), b.PageRank(), d.PageRank())
+	}
+	total := a.PageRank() + b.PageRank() + c.PageRank() + d.PageRank()
+	if total < 0.999 || total > 1.001 {
+		t.Errorf("page rank should sum to 1, got %v", total)
+	}
+}
+
+func TestCentralityLink(t *testing.T) {
+	g := &Graph{Packages: map[string]*Node{}}
+	node := func(id string, imports ...*Node) *Node {
+		n := &Node{Package: &packages.Package{ID: id}, ImportsNodes: imports}
+		g.AddNode(n)
+		g.Sorted = append(g.Sorted, n)
+		return n
+	}
+	c := node("c")
+	b := node("b")
+	a := node("a", b)
+	g.Link()
+
+	if got := a.Level(); got != 1 {
+		t.Fatalf("a: level %v, expected 1", got)
+	}
+
+	b.ImportsNodes = append(b.ImportsNodes, c)
+	g.Link()
+	if got := a.Level(); got != 2 {
+		t.Errorf("a: level %v after Link, expected 2", got)
+	}
+	if got := c.Metrics.Ca; got != 1 {
+		t.Errorf("c: afferent coupling %v after Link, expected 1", got)
+	}
+}
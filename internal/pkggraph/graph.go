@@ -3,9 +3,11 @@ package pkggraph
 import (
 	"encoding/json"
 	"sort"
+	"sync"
 
 	"golang.org/x/tools/go/packages"
 
+	"github.com/loov/goda/internal/modinfo"
 	"github.com/loov/goda/internal/stat"
 )
 
@@ -13,6 +15,11 @@ type Graph struct {
 	Packages map[string]*Node
 	Sorted   []*Node
 	stat.Stat
+
+	// centrality is calculated on first use, hence the imports
+	// must not change afterwards without calling Link.
+	centralityOnce sync.Once
+	centrality     map[*Node]centrality
 }
 
 func (g *Graph) AddNode(n *Node) {
@@ -23,8 +30,14 @@ func (g *Graph) AddNode(n *Node) {
 type Node struct {
 	*packages.Package
 	Color string
+	// EdgeColor overrides the color of edges to the imported nodes.
+	EdgeColor map[*Node]string
 
 	ImportsNodes []*Node
+	// ImportedByNodes are the nodes importing this node.
+	ImportedByNodes []*Node
+	// ImportSites are the locations of import specs in the package.
+	ImportSites []stat.ImportSite
 
 	// Stats about the current node.
 	stat.Stat
@@ -33,12 +46,17 @@ type Node struct {
 	// Stats about downstream nodes.
 	Down stat.Stat
 
+	Metrics Metrics
+
 	Errors []error
 	Graph  *Graph
 }
 
 func (n *Node) Pkg() *packages.Package { return n.Package }
 
+// ModuleInfo returns information from the go.mod of the package's module.
+func (n *Node) ModuleInfo() *modinfo.Module { return modinfo.Of(n.Package.Module) }
+
 // From creates a new graph from a map of packages.
 func From(pkgs map[string]*packages.Package) *Graph {
 	g := &Graph{Packages: map[string]*Node{}}
@@ -86,24 +104,83 @@ func From(pkgs map[string]*packages.Package) *Graph {
 		}
 	}
 
-	for _, n := range g.Packages {
-		SortNodes(n.ImportsNodes)
-	}
+	g.Link()
 
 	return g
 }
 
+// Link populates ImportedByNodes from ImportsNodes, sorts them and
+// calculates the metrics of every node.
+//
+// Graphs that are not created with From need to call Link after
+// all the nodes and imports have been added. Link also discards
+// the centrality calculated for the previous imports.
+func (g *Graph) Link() {
+	g.centralityOnce = sync.Once{}
+	g.centrality = nil
+
+	for _, n := range g.Sorted {
+		n.ImportedByNodes = nil
+	}
+	for _, n := range g.Sorted {
+		for _, dep := range n.ImportsNodes {
+			dep.ImportedByNodes = append(dep.ImportedByNodes, n)
+		}
+	}
+
+	for _, n := range g.Sorted {
+		SortNodes(n.ImportsNodes)
+		SortNodes(n.ImportedByNodes)
+		n.calculateMetrics()
+	}
+}
+
 func LoadNode(p *packages.Package) *Node {
 	node := &Node{}
 	node.Package = p
 
-	stat, errs := stat.Package(p)
+	stat, sites, errs := stat.Package(p)
 	node.Errors = append(node.Errors, errs...)
 	node.Stat = stat
+	node.ImportSites = sites
 
 	return node
 }
 
+// SitesOf returns the import sites of dst.
+func (n *Node) SitesOf(dst *Node) []stat.ImportSite {
+	var sites []stat.ImportSite
+	for _, site := range n.ImportSites {
+		if imported, ok := n.Package.Imports[site.Path]; ok && imported.ID == dst.ID {
+			sites = append(sites, site)
+		}
+	}
+	return sites
+}
+
+// BlankImports returns import paths that are only imported for side-effects.
+func (n *Node) BlankImports() []string {
+	blank := map[string]bool{}
+	for _, site := range n.ImportSites {
+		if site.Kind == stat.ImportBlank {
+			if _, ok := blank[site.Path]; !ok {
+				blank[site.Path] = true
+			}
+		} else {
+			blank[site.Path] = false
+		}
+	}
+
+	var paths []string
+	for path, only := range blank {
+		if only {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 func SortNodes(xs []*Node) {
 	sort.Slice(xs, func(i, k int) bool { return xs[i].ID < xs[k].ID })
 }
@@ -122,12 +199,15 @@ type flatNode struct {
 		Imports         map[string]string `json:",omitempty"`
 	}
 
-	ImportsNodes []string `json:",omitempty"`
+	ImportsNodes []string          `json:",omitempty"`
+	ImportSites  []stat.ImportSite `json:",omitempty"`
 
 	Stat stat.Stat
 	Up   stat.Stat
 	Down stat.Stat
 
+	Metrics Metrics
+
 	Errors []error `json:",omitempty"`
 }
 
@@ -137,6 +217,10 @@ func (p *Node) MarshalJSON() ([]byte, error) {
 		Up:     p.Up,
 		Down:   p.Down,
 		Errors: p.Errors,
+
+		Metrics: p.Metrics,
+
+		ImportSites: p.ImportSites,
 	}
 
 	flat.Package.ID = p.Package.ID
@@ -0,0 +1,30 @@
+package pkggraph
+
+import "math"
+
+// Metrics are package coupling metrics described by Robert C. Martin.
+// Couplings only consider packages in the graph.
+type Metrics struct {
+	// Ca is afferent coupling, the count of importers.
+	Ca int
+	// Ce is efferent coupling, the count of imports.
+	Ce int
+
+	// Instability is Ce / (Ca + Ce).
+	Instability float64
+	// Abstractness is the ratio of interfaces to all types.
+	Abstractness float64
+	// Distance from the main sequence |A + I - 1|.
+	Distance float64
+}
+
+func (n *Node) calculateMetrics() {
+	m := &n.Metrics
+	m.Ca = len(n.ImportedByNodes)
+	m.Ce = len(n.ImportsNodes)
+	if m.Ca+m.Ce > 0 {
+		m.Instability = float64(m.Ce) / float64(m.Ca+m.Ce)
+	}
+	m.Abstractness = n.Decls.Abstractness()
+	m.Distance = math.Abs(m.Abstractness + m.Instability - 1)
+}
@@ -0,0 +1,38 @@
+package pkgset
+
+import (
+	"errors"
+
+	"github.com/loov/goda/internal/stat"
+)
+
+// Blank returns packages that are imported by pkgs only for side-effects,
+// i.e. every import from pkgs uses `import _ "path"`.
+func Blank(pkgs Set) (Set, error) {
+	blank := Set{}
+	named := map[string]bool{}
+
+	var errs []error
+	for _, p := range pkgs {
+		_, sites, statErrs := stat.Package(p)
+		errs = append(errs, statErrs...)
+
+		for _, site := range sites {
+			dep, ok := p.Imports[site.Path]
+			if !ok {
+				continue
+			}
+			if site.Kind == stat.ImportBlank {
+				blank[dep.ID] = dep
+			} else {
+				named[dep.ID] = true
+			}
+		}
+	}
+
+	for id := range named {
+		delete(blank, id)
+	}
+
+	return blank, errors.Join(errs...)
+}
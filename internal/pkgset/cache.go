@@ -0,0 +1,74 @@
+package pkgset
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Cache shares loaded packages between multiple evaluations.
+//
+// After the first load, patterns are only resolved to package ID-s and
+// the packages are taken from the earlier loads.
+type Cache struct {
+	mu       sync.Mutex
+	universe map[string]Set
+	roots    map[string][]*packages.Package
+}
+
+// NewCache creates a new cache for loaded packages.
+func NewCache() *Cache {
+	return &Cache{
+		universe: map[string]Set{},
+		roots:    map[string][]*packages.Package{},
+	}
+}
+
+func (cache *Cache) load(config *packages.Config, patterns []string) ([]*packages.Package, error) {
+	configKey := fmt.Sprint(config.Dir, config.Env, config.BuildFlags, config.Tests)
+	patternsKey := configKey + "\x00" + strings.Join(patterns, "\x00")
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if roots, ok := cache.roots[patternsKey]; ok {
+		return roots, nil
+	}
+
+	universe, ok := cache.universe[configKey]
+	if !ok {
+		universe = New()
+		cache.universe[configKey] = universe
+	}
+
+	if len(universe) > 0 {
+		// Resolve the patterns without loading dependencies.
+		resolve := *config
+		resolve.Mode = packages.NeedName
+		found, err := packages.Load(&resolve, patterns...)
+		if err == nil {
+			roots := make([]*packages.Package, 0, len(found))
+			for _, p := range found {
+				if loaded, ok := universe[p.ID]; ok {
+					roots = append(roots, loaded)
+				}
+			}
+			if len(roots) == len(found) {
+				cache.roots[patternsKey] = roots
+				return roots, nil
+			}
+		}
+	}
+
+	roots, err := packages.Load(config, patterns...)
+	if err != nil {
+		return roots, err
+	}
+	for _, p := range roots {
+		universe.IncludeRecursive(p)
+	}
+	cache.roots[patternsKey] = roots
+	return roots, nil
+}
@@ -31,6 +31,81 @@ func Parse(_ context.Context, expr []string) (ast.Expr, error) {
 
 // Calc parses expr and computes the set of packages it describes.
 func Calc(parentContext context.Context, expr []string) (Set, error) {
+	return CalcIn(parentContext, "", expr)
+}
+
+// CalcIn is like Calc, but resolves packages relative to dir.
+func CalcIn(parentContext context.Context, dir string, expr []string) (Set, error) {
+	return calc(parentContext, dir, nil, expr)
+}
+
+// CalcCached is like Calc, but shares loaded packages using cache.
+func CalcCached(parentContext context.Context, cache *Cache, expr []string) (Set, error) {
+	return calc(parentContext, "", cache, expr)
+}
+
+// Eval computes the set of packages described by a parsed expr.
+// The settings, e.g. "cgo_enabled=0", are set in the context
+// like with "cgo_enabled=0(expr)".
+func Eval(parentContext context.Context, expr ast.Expr, settings ...string) (Set, error) {
+	return evaluate(newContext(parentContext, "", nil, settings), expr)
+}
+
+// EvalCached is like Eval, but shares loaded packages using cache.
+func EvalCached(parentContext context.Context, cache *Cache, expr ast.Expr) (Set, error) {
+	return evaluate(newContext(parentContext, "", cache, nil), expr)
+}
+
+// LoadWith loads packages matching patterns, with settings set in the context.
+func LoadWith(parentContext context.Context, settings []string, patterns ...string) (Set, error) {
+	roots, err := newContext(parentContext, "", nil, settings).Load(patterns...)
+	return NewRoot(roots...), err
+}
+
+// Settings returns the settings, e.g. "goos=linux", which are set by
+// the outermost context functions of expr for the whole expression.
+func Settings(expr ast.Expr) []string {
+	var settings []string
+	for {
+		switch e := expr.(type) {
+		case ast.Sequence:
+			if len(e.Exprs) == 0 {
+				return settings
+			}
+			expr = e.Exprs[len(e.Exprs)-1]
+			continue
+		case ast.Func:
+			if key, _ := KeyValue(e.Name); e.IsContext() && !strings.EqualFold(key, "caps") && len(e.Args) == 1 {
+				settings = append(settings, e.Name)
+				expr = e.Args[0]
+				continue
+			}
+		}
+		return settings
+	}
+}
+
+// NewContext creates a context for the working directory with settings,
+// e.g. "goos=linux", applied in order.
+func NewContext(parentContext context.Context, settings ...string) *Context {
+	return newContext(parentContext, "", nil, settings)
+}
+
+func newContext(parentContext context.Context, dir string, cache *Cache, settings []string) *Context {
+	ctx := &Context{
+		Context:   parentContext,
+		Dir:       dir,
+		Env:       Strings(os.Environ()),
+		Variables: map[string]Set{},
+		Cache:     cache,
+	}
+	for _, setting := range settings {
+		ctx.Set(KeyValue(setting))
+	}
+	return ctx
+}
+
+func calc(parentContext context.Context, dir string, cache *Cache, expr []string) (Set, error) {
 	if len(expr) == 0 {
 		expr = []string{"."}
 	}
@@ -40,6 +115,10 @@ func Calc(parentContext context.Context, expr []string) (Set, error) {
 		return New(), err
 	}
 
+	return evaluate(newContext(parentContext, dir, cache, nil), rootExpr)
+}
+
+func evaluate(rootContext *Context, rootExpr ast.Expr) (Set, error) {
 	var eval func(*Context, ast.Expr) (Set, error)
 
 	evalArgs := func(ctx *Context, exprs []ast.Expr) ([]Set, error) {
@@ -100,6 +179,20 @@ func Calc(parentContext context.Context, expr []string) (Set, error) {
 			return NewRoot(roots...), err
 
 		case ast.Func:
+			if key, value := KeyValue(e.Name); e.IsContext() && strings.EqualFold(key, "caps") {
+				capability, err := ParseCapability(value)
+				if err != nil {
+					return nil, err
+				}
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("expected 1 argument found %d", len(e.Args))
+				}
+				args, err := evalArgs(ctx, e.Args)
+				if err != nil {
+					return nil, err
+				}
+				return Caps(args[0], capability), nil
+			}
 			if e.IsContext() {
 				subctx := ctx.Clone()
 				key, value := KeyValue(e.Name)
@@ -185,6 +278,104 @@ func Calc(parentContext context.Context, expr []string) (Set, error) {
 				args, err := evalArgs(ctx, e.Args)
 				return Transitive(args[0]), err
 
+			case "blank":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("blank requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				if err != nil {
+					return nil, err
+				}
+				return Blank(args[0])
+
+			case "inits":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("inits requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				if err != nil {
+					return nil, err
+				}
+				return Inits(args[0])
+
+			case "linkname":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("linkname requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				if err != nil {
+					return nil, err
+				}
+				return Linkname(args[0])
+
+			case "unsafe":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("unsafe requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				return Unsafe(args[0]), err
+
+			case "asm":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("asm requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				return Asm(args[0]), err
+
+			case "cgo":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("cgo requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				if err != nil {
+					return nil, err
+				}
+				return Cgo(args[0])
+
+			case "cycles":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("cycles requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				return ModuleCyclePackages(args[0]), err
+
+			case "deprecated":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("deprecated requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				return Deprecated(args[0]), err
+
+			case "retracted":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("retracted requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				return Retracted(args[0]), err
+
+			case "replaced":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("replaced requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				return Replaced(args[0]), err
+
+			case "testonly":
+				if len(e.Args) != 1 {
+					return nil, fmt.Errorf("testonly requires one argument: %v", e)
+				}
+				args, err := evalArgs(ctx, e.Args)
+				if err != nil {
+					return nil, err
+				}
+				testctx := ctx.Clone()
+				testctx.Set("test", "1")
+				tests, err := evalArgs(testctx, e.Args)
+				if err != nil {
+					return nil, err
+				}
+				return TestOnly(NewAll(args[0]), NewAll(tests[0])), nil
+
 			case "deadcode":
 				if len(e.Args) != 1 {
 					return nil, fmt.Errorf("deadcode requires one argument: %v", e)
@@ -293,11 +484,7 @@ func Calc(parentContext context.Context, expr []string) (Set, error) {
 		}
 	}
 
-	return eval(&Context{
-		Context:   parentContext,
-		Env:       Strings(os.Environ()),
-		Variables: map[string]Set{},
-	}, rootExpr)
+	return eval(rootContext, rootExpr)
 }
 
 func extractLoadGroup(fn ast.Func) []string {
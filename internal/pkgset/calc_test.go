@@ -0,0 +1,30 @@
+package pkgset
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	tests := []struct {
+		expr string
+		exp  []string
+	}{
+		{expr: "./...", exp: nil},
+		{expr: "goos=linux(./...:all)", exp: []string{"goos=linux"}},
+		{expr: "goos=linux(purego=1(./cmd/x:all))", exp: []string{"goos=linux", "purego=1"}},
+		{expr: "x := ./a; cgo_enabled=0(x:all)", exp: []string{"cgo_enabled=0"}},
+		{expr: "caps=net(./...)", exp: nil},
+		{expr: "goos=linux(./a) + ./b", exp: nil},
+	}
+	for _, test := range tests {
+		root, err := Parse(context.Background(), []string{test.expr})
+		if err != nil {
+			t.Fatalf("%q: %v", test.expr, err)
+		}
+		if got := Settings(root); !slices.Equal(got, test.exp) {
+			t.Errorf("%q: got %q, expected %q", test.expr, got, test.exp)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package pkgset
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Capability is a sensitive operation a package can perform.
+type Capability string
+
+const (
+	CapExec    Capability = "exec"
+	CapFiles   Capability = "files"
+	CapNetwork Capability = "net"
+	CapPlugin  Capability = "plugin"
+	CapReflect Capability = "reflect"
+	CapSyscall Capability = "syscall"
+	CapUnsafe  Capability = "unsafe"
+)
+
+// Capabilities contains all capabilities in sorted order.
+var Capabilities = []Capability{CapExec, CapFiles, CapNetwork, CapPlugin, CapReflect, CapSyscall, CapUnsafe}
+
+// ParseCapability parses a capability name.
+func ParseCapability(name string) (Capability, error) {
+	c := Capability(strings.ToLower(name))
+	if !slices.Contains(Capabilities, c) {
+		return "", fmt.Errorf("unknown capability %q", name)
+	}
+	return c, nil
+}
+
+// importCapability returns the capability granted by importing path.
+func importCapability(importPath string) (Capability, bool) {
+	switch importPath {
+	case "os/exec":
+		return CapExec, true
+	case "os", "io/ioutil":
+		return CapFiles, true
+	case "plugin":
+		return CapPlugin, true
+	case "reflect":
+		return CapReflect, true
+	case "syscall", "golang.org/x/sys/unix", "golang.org/x/sys/windows":
+		return CapSyscall, true
+	case "unsafe":
+		return CapUnsafe, true
+	}
+	if importPath == "net" || strings.HasPrefix(importPath, "net/") {
+		return CapNetwork, true
+	}
+	return "", false
+}
+
+// identifierCapabilities overrides capabilities for packages, where
+// only some of the identifiers are sensitive.
+var identifierCapabilities = map[string]map[string]Capability{
+	"os": {
+		"Chmod": CapFiles, "Chown": CapFiles, "Chtimes": CapFiles,
+		"Create": CapFiles, "CreateTemp": CapFiles, "Link": CapFiles,
+		"Lchown": CapFiles, "Mkdir": CapFiles, "MkdirAll": CapFiles,
+		"MkdirTemp": CapFiles, "OpenFile": CapFiles, "Remove": CapFiles,
+		"RemoveAll": CapFiles, "Rename": CapFiles, "Symlink": CapFiles,
+		"Truncate": CapFiles, "WriteFile": CapFiles,
+
+		"StartProcess": CapExec,
+	},
+	"io/ioutil": {
+		"TempDir": CapFiles, "TempFile": CapFiles, "WriteFile": CapFiles,
+	},
+}
+
+// ImportCapabilities returns capabilities of p based on its imports.
+func ImportCapabilities(p *packages.Package) []Capability {
+	var caps []Capability
+	for importPath := range p.Imports {
+		if c, ok := importCapability(importPath); ok && !slices.Contains(caps, c) {
+			caps = append(caps, c)
+		}
+	}
+	slices.Sort(caps)
+	return caps
+}
+
+// IdentifierCapabilities returns capabilities of p based on the identifiers
+// it uses, e.g. importing "os" only for os.Getenv does not grant files.
+//
+// Blank and dot imports are treated as using the whole package.
+func IdentifierCapabilities(p *packages.Package) ([]Capability, error) {
+	var caps []Capability
+	add := func(c Capability) {
+		if !slices.Contains(caps, c) {
+			caps = append(caps, c)
+		}
+	}
+	use := func(importPath, ident string) {
+		if idents, ok := identifierCapabilities[importPath]; ok {
+			if c, ok := idents[ident]; ok {
+				add(c)
+			}
+			return
+		}
+		if c, ok := importCapability(importPath); ok {
+			add(c)
+		}
+	}
+
+	var errs []error
+	fset := token.NewFileSet()
+	for _, filename := range p.GoFiles {
+		f, err := parser.ParseFile(fset, filename, nil, parser.SkipObjectResolution)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %q: %w", filename, err))
+			continue
+		}
+
+		names := map[string]string{}
+		for _, spec := range f.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+
+			name := path.Base(importPath)
+			if dep, ok := p.Imports[importPath]; ok && dep.Name != "" {
+				name = dep.Name
+			}
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+
+			switch name {
+			case "_", ".":
+				if c, ok := importCapability(importPath); ok {
+					add(c)
+				}
+			default:
+				names[name] = importPath
+			}
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				if importPath, ok := names[ident.Name]; ok {
+					use(importPath, sel.Sel.Name)
+				}
+			}
+			return true
+		})
+	}
+
+	slices.Sort(caps)
+	return caps, errors.Join(errs...)
+}
+
+// Caps returns packages from a that directly or transitively use capability.
+//
+// Std packages are not considered as intermediate dependencies,
+// otherwise most packages would reach every capability.
+func Caps(a Set, capability Capability) Set {
+	holders := New()
+	a.WalkAllDependencies(func(p *packages.Package) {
+		if !IsStd(p) && slices.Contains(ImportCapabilities(p), capability) {
+			holders[p.ID] = p
+		}
+	})
+	return Reach(a, holders)
+}
@@ -0,0 +1,24 @@
+package pkgset
+
+import (
+	"errors"
+
+	"github.com/loov/goda/internal/stat"
+)
+
+// Cgo returns packages from pkgs that import "C".
+func Cgo(pkgs Set) (Set, error) {
+	result := New()
+	var errs []error
+	for id, p := range pkgs {
+		sites, siteErrs := stat.ImportSitesOf(p)
+		errs = append(errs, siteErrs...)
+		for _, site := range sites {
+			if site.Path == "C" {
+				result[id] = p
+				break
+			}
+		}
+	}
+	return result, errors.Join(errs...)
+}
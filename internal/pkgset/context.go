@@ -32,35 +32,48 @@ func replaceAliases(patterns ...string) []string {
 
 type Context struct {
 	Context context.Context
+	Dir     string
 	Tags    Strings
 	Env     Strings
 
 	Variables map[string]Set
+
+	// Cache is used for loading packages, when not nil.
+	Cache *Cache
 }
 
 func (ctx Context) Clone() *Context {
 	return &Context{
 		Context:   ctx.Context,
+		Dir:       ctx.Dir,
 		Tags:      ctx.Tags.Clone(),
 		Env:       ctx.Env.Clone(),
 		Variables: ctx.Variables,
+		Cache:     ctx.Cache,
 	}
 }
 
 func (ctx Context) Load(patterns ...string) ([]*packages.Package, error) {
-	return packages.Load(ctx.Config(), replaceAliases(patterns...)...)
+	return ctx.load(ctx.Config(), replaceAliases(patterns...))
 }
 
 func (ctx Context) LoadWithTests(patterns ...string) ([]*packages.Package, error) {
 	config := ctx.Config()
 	config.Tests = true
-	return packages.Load(config, replaceAliases(patterns...)...)
+	return ctx.load(config, replaceAliases(patterns...))
 }
 
 func (ctx Context) LoadWithoutTests(patterns ...string) ([]*packages.Package, error) {
 	config := ctx.Config()
 	config.Tests = false
-	return packages.Load(config, replaceAliases(patterns...)...)
+	return ctx.load(config, replaceAliases(patterns...))
+}
+
+func (ctx Context) load(config *packages.Config, patterns []string) ([]*packages.Package, error) {
+	if ctx.Cache != nil {
+		return ctx.Cache.load(config, patterns)
+	}
+	return packages.Load(config, patterns...)
 }
 
 func (ctx *Context) Set(key, value string) {
@@ -74,6 +87,7 @@ func (ctx *Context) Set(key, value string) {
 func (ctx Context) Config() *packages.Config {
 	config := &packages.Config{
 		Context: ctx.Context,
+		Dir:     ctx.Dir,
 		Mode:    packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedModule,
 		Env:     ctx.Env,
 		Tests:   ctx.Tags.ValueOf("test") == "1",
@@ -0,0 +1,165 @@
+package pkgset
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/tools/go/packages"
+)
+
+// Cycle is a group of modules or repositories, which import each other.
+type Cycle struct {
+	// Members are the module paths or repository roots in the cycle.
+	Members []string
+	// Edges are the package imports between the members.
+	Edges []Edge
+}
+
+// Edge is an import between two packages.
+type Edge struct {
+	From, To *packages.Package
+}
+
+// ModuleOf returns the module path of p.
+func ModuleOf(p *packages.Package) string {
+	if p.Module == nil {
+		return ""
+	}
+	return p.Module.Path
+}
+
+// RepoOf returns the module path without the major version suffix,
+// so that different major versions belong to the same repository.
+func RepoOf(p *packages.Package) string {
+	path := ModuleOf(p)
+	if prefix, _, ok := module.SplitPathVersion(path); ok {
+		return prefix
+	}
+	return path
+}
+
+// Cycles finds strongly connected components between groups of packages,
+// where keyOf returns the group of a package. Only imports within pkgs are
+// considered and packages with an empty key are ignored.
+func Cycles(pkgs Set, keyOf func(*packages.Package) string) []Cycle {
+	imports := map[string][]string{}
+	edges := map[[2]string][]Edge{}
+	for _, p := range pkgs.Sorted() {
+		from := keyOf(p)
+		if from == "" {
+			continue
+		}
+		for _, dep := range sortedImports(p) {
+			if _, ok := pkgs[dep.ID]; !ok {
+				continue
+			}
+			to := keyOf(dep)
+			if to == "" || to == from {
+				continue
+			}
+			key := [2]string{from, to}
+			if _, ok := edges[key]; !ok {
+				imports[from] = append(imports[from], to)
+			}
+			edges[key] = append(edges[key], Edge{From: p, To: dep})
+		}
+	}
+
+	var cycles []Cycle
+	for _, component := range stronglyConnected(imports) {
+		if len(component) < 2 {
+			continue
+		}
+		slices.Sort(component)
+
+		cycle := Cycle{Members: component}
+		for _, from := range component {
+			for _, to := range component {
+				cycle.Edges = append(cycle.Edges, edges[[2]string{from, to}]...)
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+
+	slices.SortFunc(cycles, func(a, b Cycle) int {
+		return strings.Compare(a.Members[0], b.Members[0])
+	})
+	return cycles
+}
+
+// ModuleCyclePackages returns packages from pkgs which import packages
+// from other modules in the same module cycle, including the imported packages.
+func ModuleCyclePackages(pkgs Set) Set {
+	result := New()
+	for _, cycle := range Cycles(pkgs, ModuleOf) {
+		for _, edge := range cycle.Edges {
+			result[edge.From.ID] = edge.From
+			result[edge.To.ID] = edge.To
+		}
+	}
+	return result
+}
+
+// stronglyConnected uses Tarjan's algorithm to find strongly connected
+// components in graph.
+func stronglyConnected(graph map[string][]string) [][]string {
+	nodes := make([]string, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	slices.Sort(nodes)
+
+	index := map[string]int{}
+	lowlink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var components [][]string
+
+	var connect func(node string)
+	connect = func(node string) {
+		index[node] = len(index)
+		lowlink[node] = index[node]
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, next := range graph[node] {
+			if _, visited := index[next]; !visited {
+				connect(next)
+				lowlink[node] = min(lowlink[node], lowlink[next])
+			} else if onStack[next] {
+				lowlink[node] = min(lowlink[node], index[next])
+			}
+		}
+
+		if lowlink[node] == index[node] {
+			var component []string
+			for {
+				last := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[last] = false
+				component = append(component, last)
+				if last == node {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, node := range nodes {
+		if _, visited := index[node]; !visited {
+			connect(node)
+		}
+	}
+	return components
+}
+
+func sortedImports(p *packages.Package) []*packages.Package {
+	deps := make([]*packages.Package, 0, len(p.Imports))
+	for _, dep := range p.Imports {
+		deps = append(deps, dep)
+	}
+	slices.SortFunc(deps, func(a, b *packages.Package) int { return strings.Compare(a.ID, b.ID) })
+	return deps
+}
@@ -0,0 +1,57 @@
+package pkgset
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestCycles(t *testing.T) {
+	pkg := func(id, module string) *packages.Package {
+		return &packages.Package{
+			ID:      id,
+			Module:  &packages.Module{Path: module},
+			Imports: map[string]*packages.Package{},
+		}
+	}
+	imports := func(from *packages.Package, to ...*packages.Package) {
+		for _, p := range to {
+			from.Imports[p.ID] = p
+		}
+	}
+
+	// a/x -> b/x -> a/y, c/x is not part of the cycle.
+	ax := pkg("example.com/a/x", "example.com/a")
+	ay := pkg("example.com/a/y", "example.com/a")
+	bx := pkg("example.com/b/x", "example.com/b")
+	b2 := pkg("example.com/b/v2/x", "example.com/b/v2")
+	cx := pkg("example.com/c/x", "example.com/c")
+	imports(ax, bx, cx)
+	imports(bx, ay)
+	imports(ay, b2)
+
+	set := New(ax, ay, bx, b2, cx)
+
+	cycles := Cycles(set, ModuleOf)
+	if len(cycles) != 1 {
+		t.Fatalf("expected 1 module cycle, got %d", len(cycles))
+	}
+	if !slices.Equal(cycles[0].Members, []string{"example.com/a", "example.com/b"}) {
+		t.Errorf("invalid members %v", cycles[0].Members)
+	}
+	if len(cycles[0].Edges) != 2 {
+		t.Errorf("expected 2 edges, got %v", cycles[0].Edges)
+	}
+
+	// b/v2 belongs to the same repository as b.
+	cycles = Cycles(set, RepoOf)
+	if len(cycles) != 1 || len(cycles[0].Edges) != 3 {
+		t.Errorf("expected repository cycle with 3 edges, got %v", cycles)
+	}
+
+	got := ModuleCyclePackages(set).IDs()
+	if !slices.Equal(got, []string{"example.com/a/x", "example.com/a/y", "example.com/b/x"}) {
+		t.Errorf("invalid cycle packages %v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package pkgset
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/stat"
+)
+
+// Inits returns packages that have init functions or package-level
+// variables initialized with function calls or composite literals.
+func Inits(pkgs Set) (Set, error) {
+	return filterStat(pkgs, func(info stat.Stat) bool {
+		return info.Decls.Init > 0 || info.Decls.InitVar > 0
+	})
+}
+
+// filterStat returns packages from pkgs, whose stats match the predicate.
+func filterStat(pkgs Set, match func(stat.Stat) bool) (Set, error) {
+	result := New()
+	var errs []error
+	for id, p := range pkgs {
+		info, _, statErrs := stat.Package(p)
+		errs = append(errs, statErrs...)
+		if match(info) {
+			result[id] = p
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// InitOrder returns packages in the order they are initialized.
+//
+// Packages are sorted by import path and at each step the first
+// package, whose imports have been initialized, is selected.
+// Imports outside of pkgs are treated as initialized.
+func InitOrder(pkgs Set) []*packages.Package {
+	byPath := func(a, b *packages.Package) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	}
+
+	pending := map[*packages.Package]int{}
+	importers := map[*packages.Package][]*packages.Package{}
+	var ready []*packages.Package
+	for _, p := range pkgs {
+		for _, dep := range p.Imports {
+			if dep, ok := pkgs[dep.ID]; ok {
+				pending[p]++
+				importers[dep] = append(importers[dep], p)
+			}
+		}
+		if pending[p] == 0 {
+			ready = append(ready, p)
+		}
+	}
+	slices.SortFunc(ready, byPath)
+
+	order := make([]*packages.Package, 0, len(pkgs))
+	for len(ready) > 0 {
+		p := ready[0]
+		ready = ready[1:]
+		order = append(order, p)
+
+		for _, importer := range importers[p] {
+			pending[importer]--
+			if pending[importer] == 0 {
+				at, _ := slices.BinarySearchFunc(ready, importer, byPath)
+				ready = slices.Insert(ready, at, importer)
+			}
+		}
+	}
+
+	return order
+}
@@ -0,0 +1,41 @@
+package pkgset
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestInitOrder(t *testing.T) {
+	pkg := func(id string, imports ...*packages.Package) *packages.Package {
+		p := &packages.Package{
+			ID:      id,
+			PkgPath: id,
+			Imports: map[string]*packages.Package{},
+		}
+		for _, dep := range imports {
+			p.Imports[dep.PkgPath] = dep
+		}
+		return p
+	}
+
+	z := pkg("z")
+	m := pkg("m")
+	b := pkg("b", z)
+	a := pkg("a", b, m)
+	// x is not part of the set below.
+	x := pkg("x")
+	c := pkg("c", x)
+
+	set := Set{"a": a, "b": b, "c": c, "m": m, "z": z}
+
+	var got []string
+	for _, p := range InitOrder(set) {
+		got = append(got, p.ID)
+	}
+	expected := []string{"c", "m", "z", "b", "a"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/modinfo"
 )
 
 // Set is a p.ID -> *packages.Package
@@ -372,3 +374,62 @@ func IsTestPkg(pkg *packages.Package) bool {
 		strings.HasSuffix(pkg.ID, "_test") ||
 		strings.HasSuffix(pkg.ID, ".test]")
 }
+
+// Deprecated returns packages from a whose module is deprecated.
+func Deprecated(a Set) Set {
+	return filterModules(a, func(mod *modinfo.Module) bool { return mod.Deprecated != "" })
+}
+
+// Retracted returns packages from a whose module version is retracted.
+func Retracted(a Set) Set {
+	return filterModules(a, (*modinfo.Module).IsRetracted)
+}
+
+// Replaced returns packages from a whose module is replaced.
+func Replaced(a Set) Set {
+	return filterModules(a, func(mod *modinfo.Module) bool { return mod.Replaced })
+}
+
+func filterModules(a Set, include func(*modinfo.Module) bool) Set {
+	rs := Set{}
+	for pid, pkg := range a {
+		if pkg.Module != nil && include(modinfo.Of(pkg.Module)) {
+			rs[pid] = pkg
+		}
+	}
+	return rs
+}
+
+// IsTestBinaryOrXTest returns whether pkg is a generated test main, e.g. "a.test",
+// or an external test package, e.g. "a_test [a.test]".
+//
+// Unlike IsTestPkg, test variants of packages, e.g. "a [a.test]", and
+// packages whose import path ends with "_test" are not included.
+func IsTestBinaryOrXTest(pkg *packages.Package) bool {
+	path, variant, isVariant := strings.Cut(pkg.ID, " [")
+	if isVariant {
+		base, isXTest := strings.CutSuffix(path, "_test")
+		return isXTest && variant == base+".test]"
+	}
+	return strings.HasSuffix(path, ".test") && pkg.Name == "main"
+}
+
+// TestOnly returns packages from test, which are not in prod,
+// where prod and test are the same packages loaded without and with tests.
+//
+// Test variants of packages, e.g. "a [b.test]", are considered the same
+// as the package itself.
+func TestOnly(prod, test Set) Set {
+	prodPaths := map[string]bool{}
+	for _, pkg := range prod {
+		prodPaths[pkg.PkgPath] = true
+	}
+
+	rs := Set{}
+	for pid, pkg := range test {
+		if !prodPaths[pkg.PkgPath] && !IsTestBinaryOrXTest(pkg) {
+			rs[pid] = pkg
+		}
+	}
+	return rs
+}
@@ -1,6 +1,7 @@
 package pkgset
 
 import (
+	"slices"
 	"testing"
 
 	"golang.org/x/tools/go/packages"
@@ -35,3 +36,50 @@ func TestSources(t *testing.T) {
 		t.Errorf("expected %q as source, got %v", "a", sources.IDs())
 	}
 }
+
+func TestTestOnly(t *testing.T) {
+	pkg := func(id, pkgPath string) *packages.Package {
+		return &packages.Package{ID: id, PkgPath: pkgPath}
+	}
+	testMain := pkg("a.test", "a.test")
+	testMain.Name = "main"
+
+	prod := NewRoot(pkg("a", "a"), pkg("b", "b"))
+	test := NewRoot(
+		pkg("a", "a"),
+		pkg("a [a.test]", "a"),
+		pkg("a_test [a.test]", "a_test"),
+		testMain,
+		pkg("b", "b"),
+		pkg("assert", "assert"),
+		pkg("helper [a.test]", "helper"),
+		pkg("mock_test", "mock_test"),
+	)
+
+	got := TestOnly(prod, test).IDs()
+	expected := []string{"assert", "helper [a.test]", "mock_test"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("got %v, expected %v", got, expected)
+	}
+}
+
+func TestIsTestBinaryOrXTest(t *testing.T) {
+	tests := []struct {
+		id, pkgPath, name string
+		exp               bool
+	}{
+		{"a.test", "a.test", "main", true},
+		{"a_test [a.test]", "a_test", "a_test", true},
+		{"a [a.test]", "a", "a", false},
+		{"helper [a.test]", "helper", "helper", false},
+		{"mock_test", "mock_test", "mock_test", false},
+		{"mock_test [a.test]", "mock_test", "mock_test", false},
+		{"example.com/x.test", "example.com/x.test", "x", false},
+	}
+	for _, test := range tests {
+		pkg := &packages.Package{ID: test.id, PkgPath: test.pkgPath, Name: test.name}
+		if got := IsTestBinaryOrXTest(pkg); got != test.exp {
+			t.Errorf("%q: got %v, expected %v", test.id, got, test.exp)
+		}
+	}
+}
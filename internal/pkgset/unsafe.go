@@ -0,0 +1,44 @@
+package pkgset
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/loov/goda/internal/stat"
+)
+
+// Linkname returns packages from pkgs that use //go:linkname directives.
+func Linkname(pkgs Set) (Set, error) {
+	result := New()
+	var errs []error
+	for id, p := range pkgs {
+		count, linknameErrs := stat.Linknames(p)
+		errs = append(errs, linknameErrs...)
+		if count > 0 {
+			result[id] = p
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// Unsafe returns packages from pkgs that import "unsafe".
+func Unsafe(pkgs Set) Set {
+	result := New()
+	for id, p := range pkgs {
+		if _, ok := p.Imports["unsafe"]; ok {
+			result[id] = p
+		}
+	}
+	return result
+}
+
+// Asm returns packages from pkgs that contain assembly files.
+func Asm(pkgs Set) Set {
+	result := New()
+	for id, p := range pkgs {
+		if slices.ContainsFunc(p.OtherFiles, stat.IsAsmFile) {
+			result[id] = p
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package pkgset
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestUnsafeFunctions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	unsafe := &packages.Package{ID: "unsafe", PkgPath: "unsafe"}
+	set := Set{
+		"plain": {
+			ID: "plain", PkgPath: "plain",
+			GoFiles: []string{write("plain.go", "package plain\n")},
+		},
+		"link": {
+			ID: "link", PkgPath: "link",
+			GoFiles: []string{write("link.go", "package link\nimport _ \"unsafe\"\n//go:linkname now time.now\nfunc now() int64\n")},
+			Imports: map[string]*packages.Package{"unsafe": unsafe},
+		},
+		"ptr": {
+			ID: "ptr", PkgPath: "ptr",
+			GoFiles: []string{write("ptr.go", "package ptr\nimport \"unsafe\"\nvar _ = unsafe.Pointer(nil)\n")},
+			Imports: map[string]*packages.Package{"unsafe": unsafe},
+		},
+		"asm": {
+			ID: "asm", PkgPath: "asm",
+			GoFiles:    []string{write("asm.go", "package asm\nfunc add(a, b int) int\n")},
+			OtherFiles: []string{filepath.Join(dir, "add_amd64.s"), filepath.Join(dir, "doc.txt")},
+		},
+	}
+
+	linkname, err := Linkname(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, test := range map[string]struct {
+		got Set
+		exp []string
+	}{
+		"linkname": {linkname, []string{"link"}},
+		"unsafe":   {Unsafe(set), []string{"link", "ptr"}},
+		"asm":      {Asm(set), []string{"asm"}},
+	} {
+		if got := test.got.IDs(); !slices.Equal(got, test.exp) {
+			t.Errorf("%s: got %v, expected %v", name, got, test.exp)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package stat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Cgo stats about native code.
+type Cgo struct {
+	// Files is the count of Go files importing "C".
+	Files int64
+	// Directives is the count of #cgo directives.
+	Directives int64
+
+	// Native source file counts.
+	C       int64
+	CXX     int64
+	ObjC    int64
+	Fortran int64
+	SWIG    int64
+	Headers int64
+}
+
+func (s *Cgo) Add(b Cgo) {
+	s.Files += b.Files
+	s.Directives += b.Directives
+	s.C += b.C
+	s.CXX += b.CXX
+	s.ObjC += b.ObjC
+	s.Fortran += b.Fortran
+	s.SWIG += b.SWIG
+	s.Headers += b.Headers
+}
+
+func (s *Cgo) Sub(b Cgo) {
+	s.Files -= b.Files
+	s.Directives -= b.Directives
+	s.C -= b.C
+	s.CXX -= b.CXX
+	s.ObjC -= b.ObjC
+	s.Fortran -= b.Fortran
+	s.SWIG -= b.SWIG
+	s.Headers -= b.Headers
+}
+
+// Native returns the count of native source files.
+func (s *Cgo) Native() int64 {
+	return s.C + s.CXX + s.ObjC + s.Fortran + s.SWIG + s.Headers
+}
+
+// AddNativeFile counts filename based on its extension.
+func (s *Cgo) AddNativeFile(filename string) {
+	switch filepath.Ext(filename) {
+	case ".c":
+		s.C++
+	case ".cc", ".cpp", ".cxx":
+		s.CXX++
+	case ".m", ".mm":
+		s.ObjC++
+	case ".f", ".F", ".for", ".f90":
+		s.Fortran++
+	case ".swig", ".swigcxx":
+		s.SWIG++
+	case ".h", ".hh", ".hpp", ".hxx":
+		s.Headers++
+	}
+}
+
+// CgoDirective is a #cgo directive, e.g.
+//
+//	#cgo linux LDFLAGS: -lssl
+type CgoDirective struct {
+	File string
+	Line int
+
+	// Constraint is the optional build constraint.
+	Constraint string `json:",omitempty"`
+	// Flag is one of CFLAGS, CPPFLAGS, CXXFLAGS, FFLAGS, LDFLAGS or pkg-config.
+	Flag string
+	Args []string `json:",omitempty"`
+}
+
+func CgoFromAst(fset *token.FileSet, f *ast.File) Cgo {
+	stat := Cgo{}
+	for _, spec := range f.Imports {
+		if spec.Path.Value == `"C"` {
+			stat.Files++
+		}
+	}
+	stat.Directives = int64(len(CgoDirectivesFromAst(fset, f)))
+	return stat
+}
+
+// CgoDirectivesOf parses #cgo directives of p.
+func CgoDirectivesOf(p *packages.Package) ([]CgoDirective, []error) {
+	var directives []CgoDirective
+	var errs []error
+
+	fset := token.NewFileSet()
+	for _, filename := range p.GoFiles {
+		f, err := parser.ParseFile(fset, filename, nil, parser.ImportsOnly|parser.ParseComments)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %q: %w", filename, err))
+			continue
+		}
+		directives = append(directives, CgoDirectivesFromAst(fset, f)...)
+	}
+
+	return directives, errs
+}
+
+func CgoDirectivesFromAst(fset *token.FileSet, f *ast.File) []CgoDirective {
+	var directives []CgoDirective
+	for _, decl := range f.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range decl.Specs {
+			spec := spec.(*ast.ImportSpec)
+			if spec.Path.Value != `"C"` {
+				continue
+			}
+			doc := spec.Doc
+			if doc == nil {
+				doc = decl.Doc
+			}
+			if doc == nil {
+				continue
+			}
+
+			for _, c := range doc.List {
+				pos := fset.Position(c.Pos())
+				text := strings.TrimPrefix(c.Text, "//")
+				text = strings.TrimPrefix(text, "/*")
+				text = strings.TrimSuffix(text, "*/")
+				for i, line := range strings.Split(text, "\n") {
+					if directive, ok := parseCgoDirective(line); ok {
+						directive.File = pos.Filename
+						directive.Line = pos.Line + i
+						directives = append(directives, directive)
+					}
+				}
+			}
+		}
+	}
+	return directives
+}
+
+// parseCgoDirective parses "#cgo [constraint] FLAG: args".
+func parseCgoDirective(line string) (CgoDirective, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), "#cgo ")
+	if !ok {
+		return CgoDirective{}, false
+	}
+	head, args, ok := strings.Cut(rest, ":")
+	if !ok {
+		return CgoDirective{}, false
+	}
+	fields := strings.Fields(head)
+	if len(fields) == 0 {
+		return CgoDirective{}, false
+	}
+
+	directive := CgoDirective{
+		Constraint: strings.Join(fields[:len(fields)-1], " "),
+		Flag:       fields[len(fields)-1],
+	}
+	directive.Args, ok = splitQuoted(args)
+	return directive, ok
+}
+
+// splitQuoted splits s into fields like cgo does, where a field can be
+// quoted with single or double quotes and backslash escapes a character.
+func splitQuoted(s string) ([]string, bool) {
+	var fields []string
+	var field []rune
+	var quote rune
+	escaped, inField := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped, inField = true, true
+			continue
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+		case r == '"' || r == '\'':
+			quote, inField = r, true
+			continue
+		case unicode.IsSpace(r):
+			if inField {
+				fields = append(fields, string(field))
+				field, inField = field[:0], false
+			}
+			continue
+		}
+		field = append(field, r)
+		inField = true
+	}
+	if inField {
+		fields = append(fields, string(field))
+	}
+	return fields, quote == 0 && !escaped
+}
+
+// CgoLibraries returns the system libraries linked by directives,
+// from "-l" and "-framework" LDFLAGS and pkg-config packages.
+// Frameworks are returned with ".framework" suffix.
+func CgoLibraries(directives []CgoDirective) []string {
+	var libs []string
+	add := func(lib string) {
+		if lib != "" && !slices.Contains(libs, lib) {
+			libs = append(libs, lib)
+		}
+	}
+
+	for _, directive := range directives {
+		switch directive.Flag {
+		case "LDFLAGS":
+			for i, arg := range directive.Args {
+				if arg == "-l" && i+1 < len(directive.Args) {
+					add(directive.Args[i+1])
+				} else if arg == "-framework" && i+1 < len(directive.Args) {
+					add(directive.Args[i+1] + ".framework")
+				} else if lib, ok := strings.CutPrefix(arg, "-l"); ok {
+					add(lib)
+				} else if strings.HasSuffix(arg, ".a") || strings.HasSuffix(arg, ".so") {
+					add(filepath.Base(arg))
+				}
+			}
+		case "pkg-config":
+			for _, arg := range directive.Args {
+				if !strings.HasPrefix(arg, "-") {
+					add(arg)
+				}
+			}
+		}
+	}
+
+	slices.Sort(libs)
+	return libs
+}
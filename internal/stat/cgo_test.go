@@ -0,0 +1,63 @@
+package stat
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseCgoDirective(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+		exp  CgoDirective
+	}{
+		{line: `#cgo LDFLAGS: -lm`, ok: true, exp: CgoDirective{Flag: "LDFLAGS", Args: []string{"-lm"}}},
+		{line: ` #cgo linux,amd64 CFLAGS: -DX=1 -O2`, ok: true, exp: CgoDirective{Constraint: "linux,amd64", Flag: "CFLAGS", Args: []string{"-DX=1", "-O2"}}},
+		{line: `#cgo CFLAGS: "-I/path with space" '-DNAME="x y"'`, ok: true, exp: CgoDirective{Flag: "CFLAGS", Args: []string{"-I/path with space", `-DNAME="x y"`}}},
+		{line: `#cgo CFLAGS: -I/a\ b`, ok: true, exp: CgoDirective{Flag: "CFLAGS", Args: []string{"-I/a b"}}},
+		{line: `#cgo darwin LDFLAGS: -framework CoreFoundation`, ok: true, exp: CgoDirective{Constraint: "darwin", Flag: "LDFLAGS", Args: []string{"-framework", "CoreFoundation"}}},
+		{line: `#cgo pkg-config: gtk+-3.0`, ok: true, exp: CgoDirective{Flag: "pkg-config", Args: []string{"gtk+-3.0"}}},
+		{line: `#cgo CFLAGS: "-unterminated`, ok: false},
+		{line: `#cgo missing colon`, ok: false},
+		{line: `#include <stdio.h>`, ok: false},
+	}
+
+	for _, test := range tests {
+		got, ok := parseCgoDirective(test.line)
+		if ok != test.ok {
+			t.Errorf("%q: got ok=%v, expected %v", test.line, ok, test.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got.Constraint != test.exp.Constraint || got.Flag != test.exp.Flag || !slices.Equal(got.Args, test.exp.Args) {
+			t.Errorf("%q: got %+v, expected %+v", test.line, got, test.exp)
+		}
+	}
+}
+
+func TestCgoLibraries(t *testing.T) {
+	tests := []struct {
+		directives []CgoDirective
+		exp        []string
+	}{
+		{[]CgoDirective{{Flag: "LDFLAGS", Args: []string{"-lm", "-l", "pthread"}}}, []string{"m", "pthread"}},
+		{[]CgoDirective{{Flag: "LDFLAGS", Args: []string{"-framework", "CoreFoundation", "-framework", "Security"}}}, []string{"CoreFoundation.framework", "Security.framework"}},
+		{[]CgoDirective{{Flag: "LDFLAGS", Args: []string{"-L/usr/lib", "/opt/lib/libfoo.a", "-Wl,-rpath,/x"}}}, []string{"libfoo.a"}},
+		{[]CgoDirective{{Flag: "pkg-config", Args: []string{"--static", "sqlite3"}}}, []string{"sqlite3"}},
+		{[]CgoDirective{{Flag: "CFLAGS", Args: []string{"-lnotalib"}}}, nil},
+		{[]CgoDirective{
+			{Flag: "LDFLAGS", Args: []string{"-lz"}},
+			{Flag: "pkg-config", Args: []string{"zlib"}},
+			{Flag: "LDFLAGS", Args: []string{"-lz"}},
+		}, []string{"z", "zlib"}},
+	}
+
+	for _, test := range tests {
+		got := CgoLibraries(test.directives)
+		if !slices.Equal(got, test.exp) {
+			t.Errorf("%+v: got %q, expected %q", test.directives, got, test.exp)
+		}
+	}
+}
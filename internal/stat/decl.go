@@ -12,6 +12,19 @@ type Decls struct {
 	Const int64
 	Var   int64
 	Other int64
+
+	// Interface is the count of interface type specs.
+	Interface int64
+	// Concrete is the count of non-interface type specs, excluding aliases.
+	Concrete int64
+
+	// Init is the count of init functions.
+	Init int64
+	// InitVar is the count of package-level variables, which are
+	// initialized with function calls or composite literals.
+	// It's an upper bound, since conversions to named types,
+	// e.g. `time.Duration(5)`, can't be told apart from calls.
+	InitVar int64
 }
 
 func (s *Decls) Add(b Decls) {
@@ -20,6 +33,10 @@ func (s *Decls) Add(b Decls) {
 	s.Const += b.Const
 	s.Var += b.Var
 	s.Other += b.Other
+	s.Interface += b.Interface
+	s.Concrete += b.Concrete
+	s.Init += b.Init
+	s.InitVar += b.InitVar
 }
 
 func (s *Decls) Sub(b Decls) {
@@ -28,12 +45,24 @@ func (s *Decls) Sub(b Decls) {
 	s.Const -= b.Const
 	s.Var -= b.Var
 	s.Other -= b.Other
+	s.Interface -= b.Interface
+	s.Concrete -= b.Concrete
+	s.Init -= b.Init
+	s.InitVar -= b.InitVar
 }
 
 func (s *Decls) Total() int64 {
 	return s.Func + s.Type + s.Const + s.Var + s.Other
 }
 
+// Abstractness returns the ratio of interfaces to all types.
+func (s *Decls) Abstractness() float64 {
+	if s.Interface+s.Concrete == 0 {
+		return 0
+	}
+	return float64(s.Interface) / float64(s.Interface+s.Concrete)
+}
+
 func DeclsFromAst(f *ast.File) Decls {
 	stat := Decls{}
 	for _, decl := range f.Decls {
@@ -42,8 +71,20 @@ func DeclsFromAst(f *ast.File) Decls {
 			switch decl.Tok {
 			case token.TYPE:
 				stat.Type++
+				for _, spec := range decl.Specs {
+					spec, ok := spec.(*ast.TypeSpec)
+					if !ok || spec.Assign.IsValid() {
+						continue
+					}
+					if _, ok := spec.Type.(*ast.InterfaceType); ok {
+						stat.Interface++
+					} else {
+						stat.Concrete++
+					}
+				}
 			case token.VAR:
 				stat.Var++
+				stat.InitVar += initializedVars(decl)
 			case token.CONST:
 				stat.Const++
 			default:
@@ -51,9 +92,94 @@ func DeclsFromAst(f *ast.File) Decls {
 			}
 		case *ast.FuncDecl:
 			stat.Func++
+			if decl.Recv == nil && decl.Name.Name == "init" {
+				stat.Init++
+			}
 		default:
 			stat.Other++
 		}
 	}
 	return stat
 }
+
+// initializedVars counts variables that require running code during
+// package initialization.
+func initializedVars(decl *ast.GenDecl) int64 {
+	count := int64(0)
+	for _, spec := range decl.Specs {
+		spec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if len(spec.Values) == 1 && len(spec.Names) > 1 {
+			// var a, b = f()
+			if isDynamic(spec.Values[0]) {
+				count += int64(len(spec.Names))
+			}
+			continue
+		}
+		for _, value := range spec.Values {
+			if isDynamic(value) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// isDynamic returns whether expr contains a function call or
+// a composite literal.
+//
+// Without type information, conversions to named types such as
+// `time.Duration(5)` look like calls, hence the result is an upper bound.
+func isDynamic(expr ast.Expr) bool {
+	dynamic := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if isConversion(n) {
+				return true
+			}
+			dynamic = true
+		case *ast.CompositeLit:
+			dynamic = true
+		}
+		return !dynamic
+	})
+	return dynamic
+}
+
+// isConversion returns whether call is syntactically a type conversion,
+// e.g. `[]byte("x")`, `int64(5)` or `(*T)(nil)`.
+func isConversion(call *ast.CallExpr) bool {
+	if len(call.Args) != 1 {
+		return false
+	}
+	fun := call.Fun
+	for {
+		paren, ok := fun.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = paren.X
+	}
+
+	switch fun := fun.(type) {
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType:
+		return true
+	case *ast.StarExpr:
+		return true
+	case *ast.Ident:
+		return predeclaredTypes[fun.Name]
+	}
+	return false
+}
+
+var predeclaredTypes = map[string]bool{
+	"bool": true, "byte": true, "rune": true, "string": true, "error": true, "any": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"float32": true, "float64": true, "complex64": true, "complex128": true,
+}
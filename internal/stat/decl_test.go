@@ -0,0 +1,37 @@
+package stat
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestInitVar(t *testing.T) {
+	tests := []struct {
+		src string
+		exp int64
+	}{
+		{src: `var x = 1`, exp: 0},
+		{src: `var x = f()`, exp: 1},
+		{src: `var a, b = f()`, exp: 2},
+		{src: `var x = T{}`, exp: 1},
+		{src: `var x = func() int { return f() }`, exp: 0},
+		{src: `var _ I = (*T)(nil)`, exp: 0},
+		{src: `var x = []byte("x")`, exp: 0},
+		{src: `var x = int64(5) + uint8(1)`, exp: 0},
+		{src: `var x = map[string]int(nil)`, exp: 0},
+		{src: `var x = string(f())`, exp: 1},
+		// Conversions to named types are counted.
+		{src: `var x = time.Duration(5)`, exp: 1},
+	}
+
+	for _, test := range tests {
+		f, err := parser.ParseFile(token.NewFileSet(), "a.go", "package a\n"+test.src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := DeclsFromAst(f).InitVar; got != test.exp {
+			t.Errorf("%q: got %d, expected %d", test.src, got, test.exp)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package stat
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ImportKind describes how a package is imported.
+type ImportKind string
+
+const (
+	// ImportRegular is an import without a name.
+	ImportRegular ImportKind = ""
+	// ImportBlank is an import for side-effects, e.g. `import _ "embed"`.
+	ImportBlank ImportKind = "blank"
+	// ImportDot is an import into the file scope, e.g. `import . "fmt"`.
+	ImportDot ImportKind = "dot"
+	// ImportRenamed is an import with a different name, e.g. `import f "fmt"`.
+	ImportRenamed ImportKind = "renamed"
+)
+
+// ImportSite is the location of an import spec.
+type ImportSite struct {
+	// Path is the import path as written in the source.
+	Path string
+
+	File   string
+	Line   int
+	Column int
+
+	// Name is the explicit package name, if any.
+	Name string     `json:",omitempty"`
+	Kind ImportKind `json:",omitempty"`
+	// Test is true when the import is in a _test.go file.
+	Test bool `json:",omitempty"`
+}
+
+// Position returns the location in file:line:column format.
+func (site ImportSite) Position() string {
+	return site.File + ":" + strconv.Itoa(site.Line) + ":" + strconv.Itoa(site.Column)
+}
+
+// ImportSitesOf parses only the imports of p.
+func ImportSitesOf(p *packages.Package) ([]ImportSite, []error) {
+	var sites []ImportSite
+	var errs []error
+
+	fset := token.NewFileSet()
+	for _, filename := range p.GoFiles {
+		f, err := parser.ParseFile(fset, filename, nil, parser.ImportsOnly)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %q: %w", filename, err))
+			continue
+		}
+		sites = append(sites, ImportSitesFromAst(fset, f)...)
+	}
+
+	return sites, errs
+}
+
+func ImportSitesFromAst(fset *token.FileSet, f *ast.File) []ImportSite {
+	var sites []ImportSite
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		pos := fset.Position(spec.Pos())
+		site := ImportSite{
+			Path:   path,
+			File:   pos.Filename,
+			Line:   pos.Line,
+			Column: pos.Column,
+			Test:   strings.HasSuffix(pos.Filename, "_test.go"),
+		}
+		if spec.Name != nil {
+			site.Name = spec.Name.Name
+			switch site.Name {
+			case "_":
+				site.Kind = ImportBlank
+			case ".":
+				site.Kind = ImportDot
+			default:
+				site.Kind = ImportRenamed
+			}
+		}
+		sites = append(sites, site)
+	}
+	return sites
+}
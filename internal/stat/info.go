@@ -6,6 +6,7 @@ import (
 	"go/parser"
 	"go/token"
 	"os"
+	"sync"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -18,6 +19,8 @@ type Stat struct {
 
 	Decls  Decls
 	Tokens Tokens
+	Unsafe Unsafe
+	Cgo    Cgo
 }
 
 func (info *Stat) AllFiles() Source {
@@ -33,6 +36,8 @@ func (s *Stat) Add(b Stat) {
 	s.OtherFiles.Add(b.OtherFiles)
 	s.Decls.Add(b.Decls)
 	s.Tokens.Add(b.Tokens)
+	s.Unsafe.Add(b.Unsafe)
+	s.Cgo.Add(b.Cgo)
 }
 
 func (s *Stat) Sub(b Stat) {
@@ -41,10 +46,48 @@ func (s *Stat) Sub(b Stat) {
 	s.OtherFiles.Sub(b.OtherFiles)
 	s.Decls.Sub(b.Decls)
 	s.Tokens.Sub(b.Tokens)
+	s.Unsafe.Sub(b.Unsafe)
+	s.Cgo.Sub(b.Cgo)
 }
 
-func Package(p *packages.Package) (Stat, []error) {
+type packageResult struct {
+	info  Stat
+	sites []ImportSite
+	errs  []error
+}
+
+var packageCache struct {
+	sync.Mutex
+	results map[*packages.Package]*packageResult
+}
+
+// Package calculates stats and import sites of p.
+//
+// The results are cached, since expression functions and graphs
+// often need the same packages.
+func Package(p *packages.Package) (Stat, []ImportSite, []error) {
+	packageCache.Lock()
+	result, ok := packageCache.results[p]
+	packageCache.Unlock()
+	if ok {
+		return result.info, result.sites, result.errs
+	}
+
+	info, sites, errs := calculate(p)
+
+	packageCache.Lock()
+	if packageCache.results == nil {
+		packageCache.results = map[*packages.Package]*packageResult{}
+	}
+	packageCache.results[p] = &packageResult{info: info, sites: sites, errs: errs}
+	packageCache.Unlock()
+
+	return info, sites, errs
+}
+
+func calculate(p *packages.Package) (Stat, []ImportSite, []error) {
 	var info Stat
+	var sites []ImportSite
 	var errs []error
 
 	info.PackageCount = 1
@@ -69,9 +112,16 @@ func Package(p *packages.Package) (Stat, []error) {
 
 		info.Decls.Add(DeclsFromAst(f))
 		info.Tokens.Add(TokensFromAst(f))
+		info.Unsafe.Add(UnsafeFromAst(f))
+		info.Cgo.Add(CgoFromAst(fset, f))
+		sites = append(sites, ImportSitesFromAst(fset, f)...)
 	}
 
 	for _, filename := range p.OtherFiles {
+		if IsAsmFile(filename) {
+			info.Unsafe.Asm++
+		}
+		info.Cgo.AddNativeFile(filename)
 		count, err := SourceFromPath(filename)
 		info.OtherFiles.Add(count)
 		if err != nil {
@@ -82,5 +132,5 @@ func Package(p *packages.Package) (Stat, []error) {
 		}
 	}
 
-	return info, errs
+	return info, sites, errs
 }
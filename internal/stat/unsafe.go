@@ -0,0 +1,136 @@
+package stat
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Unsafe stats about code that bypasses the type system or the toolchain.
+type Unsafe struct {
+	// Imports is the count of files importing "unsafe".
+	Imports int64
+	// Pointer is the count of unsafe.Pointer conversions.
+	Pointer int64
+	// Linkname is the count of //go:linkname directives.
+	Linkname int64
+	// Nosplit is the count of //go:nosplit directives.
+	Nosplit int64
+	// Noescape is the count of //go:noescape directives.
+	Noescape int64
+	// Asm is the count of assembly files.
+	Asm int64
+}
+
+func (s *Unsafe) Add(b Unsafe) {
+	s.Imports += b.Imports
+	s.Pointer += b.Pointer
+	s.Linkname += b.Linkname
+	s.Nosplit += b.Nosplit
+	s.Noescape += b.Noescape
+	s.Asm += b.Asm
+}
+
+func (s *Unsafe) Sub(b Unsafe) {
+	s.Imports -= b.Imports
+	s.Pointer -= b.Pointer
+	s.Linkname -= b.Linkname
+	s.Nosplit -= b.Nosplit
+	s.Noescape -= b.Noescape
+	s.Asm -= b.Asm
+}
+
+func UnsafeFromAst(f *ast.File) Unsafe {
+	stat := Unsafe{}
+
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			switch {
+			case strings.HasPrefix(c.Text, "//go:linkname "):
+				stat.Linkname++
+			case strings.HasPrefix(c.Text, "//go:nosplit"):
+				stat.Nosplit++
+			case strings.HasPrefix(c.Text, "//go:noescape"):
+				stat.Noescape++
+			}
+		}
+	}
+
+	name := ""
+	for _, spec := range f.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == "unsafe" {
+			stat.Imports++
+			switch {
+			case spec.Name == nil:
+				name = "unsafe"
+			case spec.Name.Name != "_":
+				name = spec.Name.Name
+			}
+		}
+	}
+	if name == "" {
+		return stat
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if name == "." {
+			if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "Pointer" {
+				stat.Pointer++
+			}
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Pointer" {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == name {
+				stat.Pointer++
+			}
+		}
+		return true
+	})
+
+	return stat
+}
+
+// IsAsmFile returns whether filename is an assembly file.
+func IsAsmFile(filename string) bool {
+	return strings.HasSuffix(filename, ".s") || strings.HasSuffix(filename, ".S")
+}
+
+// Linknames counts //go:linkname directives in p.
+//
+// Unlike Package, it only parses files which contain the directive.
+func Linknames(p *packages.Package) (int64, []error) {
+	var count int64
+	var errs []error
+
+	fset := token.NewFileSet()
+	for _, filename := range p.GoFiles {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %q: %w", filename, err))
+			continue
+		}
+		if !bytes.Contains(src, []byte("//go:linkname ")) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse %q: %w", filename, err))
+			continue
+		}
+		count += UnsafeFromAst(f).Linkname
+	}
+
+	return count, errs
+}
@@ -0,0 +1,94 @@
+package stat
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestUnsafeFromAst(t *testing.T) {
+	tests := []struct {
+		src string
+		exp Unsafe
+	}{
+		{src: "package a\n", exp: Unsafe{}},
+		{
+			src: `package a
+import "unsafe"
+import _ "unsafe"
+
+//go:linkname now time.now
+func now() (int64, int32, int64)
+
+//go:nosplit
+//go:noescape
+func f(p unsafe.Pointer)
+
+var _ = unsafe.Pointer(nil)
+var _ = unsafe.Sizeof(0)
+`,
+			exp: Unsafe{Imports: 2, Pointer: 1, Linkname: 1, Nosplit: 1, Noescape: 1},
+		},
+		{
+			src: `package a
+import u "unsafe"
+var _ = u.Pointer(nil)
+var _ = unsafe.Pointer(nil)
+`,
+			exp: Unsafe{Imports: 1, Pointer: 1},
+		},
+		{
+			src: `package a
+import . "unsafe"
+var _ = Pointer(nil)
+`,
+			exp: Unsafe{Imports: 1, Pointer: 1},
+		},
+		{
+			src: `package a
+import _ "unsafe"
+// go:linkname is not a directive
+var _ = "//go:linkname x y"
+`,
+			exp: Unsafe{Imports: 1},
+		},
+	}
+
+	for _, test := range tests {
+		f, err := parser.ParseFile(token.NewFileSet(), "a.go", test.src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := UnsafeFromAst(f); got != test.exp {
+			t.Errorf("got %+v, expected %+v\n%s", got, test.exp, test.src)
+		}
+	}
+}
+
+func TestLinknames(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	p := &packages.Package{GoFiles: []string{
+		write("a.go", "package a\nimport _ \"unsafe\"\n//go:linkname x y.x\n//go:linkname z y.z\nvar x, z int\n"),
+		write("b.go", "package a\nvar _ = 1\n"),
+		write("c.go", "package a\n// not parsed //go:linkname x\nvar _ = \"//go:linkname x y\"\n"),
+	}}
+	count, errs := Linknames(p)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if count != 2 {
+		t.Errorf("got %d linknames, expected 2", count)
+	}
+}
@@ -7,3 +7,8 @@ import (
 func Parse(t string) (*template.Template, error) {
 	return template.New("").Funcs(numericFuncs()).Funcs(stringFuncs()).Parse(t)
 }
+
+// ParseWith parses t with additional template functions.
+func ParseWith(t string, funcs template.FuncMap) (*template.Template, error) {
+	return template.New("").Funcs(numericFuncs()).Funcs(stringFuncs()).Funcs(funcs).Parse(t)
+}
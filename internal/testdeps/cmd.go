@@ -0,0 +1,130 @@
+package testdeps
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/pkgset"
+)
+
+type Command struct {
+	printStandard bool
+	json          bool
+}
+
+func (*Command) Name() string     { return "testdeps" }
+func (*Command) Synopsis() string { return "Print dependencies that are only used by tests." }
+func (*Command) Usage() string {
+	return `testdeps <expr>:
+	Print whether dependencies of expr are used in production code,
+	only in tests or both, for packages and modules.
+
+	For test-only packages it prints the imports in _test.go files
+	which introduce them, and the source stats they add.
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.json, "json", false, "print as json")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	expr := f.Args()
+	if len(expr) == 0 {
+		expr = []string{"./..."}
+	}
+
+	root, err := pkgset.Parse(ctx, expr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	prod, err := pkgset.Eval(ctx, root)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	test, err := pkgset.Eval(ctx, root, "test=1")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+
+	prod, test = pkgset.NewAll(prod), pkgset.NewAll(test)
+	if !cmd.printStandard {
+		prod = pkgset.Subtract(prod, pkgset.Std())
+		test = pkgset.Subtract(test, pkgset.Std())
+	}
+
+	report, errs := Analyze(prod, test)
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err.Error())
+	}
+
+	if cmd.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
+	}
+
+	wd, _ := os.Getwd()
+	relative := func(file string) string {
+		if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+		return file
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer func() { _ = w.Flush() }()
+
+	fmt.Fprintln(w, "MODULE\tCLASS\tPACKAGES\tTEST-ONLY SIZE\tTEST-ONLY LINES")
+	for _, mod := range report.Modules {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", mod.Path, mod.Class, mod.Packages, mod.TestOnly.AllFiles().Size, mod.TestOnly.Go.Lines)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "PACKAGE\tCLASS\tSIZE\tLINES\tINTRODUCED BY")
+	for _, pkg := range report.Packages {
+		if pkg.Class != TestOnly {
+			fmt.Fprintf(w, "%v\t%v\t\t\t\n", pkg.PkgPath, pkg.Class)
+			continue
+		}
+
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t", pkg.PkgPath, pkg.Class, pkg.Stat.AllFiles().Size, pkg.Stat.Go.Lines)
+		if len(pkg.IntroducedBy) == 0 {
+			fmt.Fprintln(w, "-")
+		}
+		for i, site := range pkg.IntroducedBy {
+			if i > 0 {
+				fmt.Fprint(w, "\t\t\t\t")
+			}
+			fmt.Fprintf(w, "%v (%v)\n", relative(site.Position()), site.Importer)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "test-only total: %d packages, %v, %d lines of Go\n",
+		report.TestOnly.PackageCount, report.TestOnly.AllFiles().Size, report.TestOnly.Go.Lines)
+
+	return subcommands.ExitSuccess
+}
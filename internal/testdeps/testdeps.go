@@ -0,0 +1,207 @@
+package testdeps
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/stat"
+)
+
+// Class describes why a package or module is a dependency.
+type Class string
+
+const (
+	// Prod dependencies are only used by non-test code.
+	Prod Class = "prod"
+	// TestOnly dependencies are only used by tests.
+	TestOnly Class = "test-only"
+	// Both dependencies are used by non-test code and by tests.
+	Both Class = "both"
+)
+
+func classify(prod, test bool) Class {
+	switch {
+	case prod && test:
+		return Both
+	case test:
+		return TestOnly
+	default:
+		return Prod
+	}
+}
+
+// Package describes a dependency.
+type Package struct {
+	PkgPath string
+	Module  string `json:",omitempty"`
+	Class   Class
+
+	// Stat is the source stat, only calculated for test-only packages.
+	Stat stat.Stat
+	// IntroducedBy are the imports in test files, which make
+	// the test-only package a dependency.
+	IntroducedBy []Site `json:",omitempty"`
+}
+
+// Site is an import in a test file.
+type Site struct {
+	// Importer is the package containing the test file,
+	// e.g. "example.com/a" or "example.com/a_test" for xtests.
+	Importer string
+	stat.ImportSite
+}
+
+// Module describes a module dependency.
+type Module struct {
+	Path     string
+	Class    Class
+	Packages int
+	// TestOnly is the stat of test-only packages in the module.
+	TestOnly stat.Stat
+}
+
+// Report classifies dependencies.
+type Report struct {
+	Packages []*Package
+	Modules  []*Module
+	// TestOnly is the stat of all test-only packages.
+	TestOnly stat.Stat
+}
+
+// Analyze classifies packages in test, where prod and test are the same
+// packages and their dependencies loaded without and with tests.
+func Analyze(prod, test pkgset.Set) (*Report, []error) {
+	var errs []error
+
+	prodPaths := map[string]bool{}
+	for _, p := range prod {
+		prodPaths[p.PkgPath] = true
+	}
+	testOnly := map[string]*packages.Package{}
+	for _, p := range pkgset.TestOnly(prod, test) {
+		// Prefer the package over its test variants.
+		if prev, ok := testOnly[p.PkgPath]; !ok || p.ID == p.PkgPath && prev.ID != prev.PkgPath {
+			testOnly[p.PkgPath] = p
+		}
+	}
+
+	// Find packages reachable from imports in test files.
+	reach := map[string][]string{}
+	reachFrom := func(p *packages.Package) []string {
+		if paths, ok := reach[p.ID]; ok {
+			return paths
+		}
+		seen := map[string]bool{}
+		var paths []string
+		var walk func(p *packages.Package)
+		walk = func(p *packages.Package) {
+			if _, ok := test[p.ID]; !ok || seen[p.ID] {
+				return
+			}
+			seen[p.ID] = true
+			paths = append(paths, p.PkgPath)
+			for _, dep := range p.Imports {
+				walk(dep)
+			}
+		}
+		walk(p)
+		reach[p.ID] = paths
+		return paths
+	}
+
+	usedByTests := map[string]bool{}
+	introducedBy := map[string][]Site{}
+	for _, p := range test.Sorted() {
+		if !hasTestFiles(p) {
+			continue
+		}
+		sites, siteErrs := stat.ImportSitesOf(p)
+		errs = append(errs, siteErrs...)
+		for _, site := range sites {
+			imported, ok := p.Imports[site.Path]
+			if !site.Test || !ok {
+				continue
+			}
+			for _, path := range reachFrom(imported) {
+				usedByTests[path] = true
+				if _, ok := testOnly[path]; ok {
+					introducedBy[path] = append(introducedBy[path], Site{
+						Importer:   p.PkgPath,
+						ImportSite: site,
+					})
+				}
+			}
+		}
+	}
+
+	report := &Report{}
+	packagesByPath := map[string]*Package{}
+	for _, p := range test {
+		if pkgset.IsTestBinaryOrXTest(p) {
+			continue
+		}
+		if _, ok := packagesByPath[p.PkgPath]; ok {
+			continue
+		}
+
+		_, isTestOnly := testOnly[p.PkgPath]
+		pkg := &Package{
+			PkgPath: p.PkgPath,
+			Class:   classify(prodPaths[p.PkgPath], isTestOnly || usedByTests[p.PkgPath]),
+		}
+		if p.Module != nil {
+			pkg.Module = p.Module.Path
+		}
+		if isTestOnly {
+			var statErrs []error
+			pkg.Stat, _, statErrs = stat.Package(testOnly[p.PkgPath])
+			errs = append(errs, statErrs...)
+			pkg.IntroducedBy = introducedBy[p.PkgPath]
+			report.TestOnly.Add(pkg.Stat)
+		}
+
+		packagesByPath[p.PkgPath] = pkg
+		report.Packages = append(report.Packages, pkg)
+	}
+	sort.Slice(report.Packages, func(i, k int) bool {
+		return report.Packages[i].PkgPath < report.Packages[k].PkgPath
+	})
+
+	modulesByPath := map[string]*Module{}
+	prodModule, testModule := map[string]bool{}, map[string]bool{}
+	for _, pkg := range report.Packages {
+		if pkg.Module == "" {
+			continue
+		}
+		mod, ok := modulesByPath[pkg.Module]
+		if !ok {
+			mod = &Module{Path: pkg.Module}
+			modulesByPath[pkg.Module] = mod
+			report.Modules = append(report.Modules, mod)
+		}
+		mod.Packages++
+		mod.TestOnly.Add(pkg.Stat)
+		prodModule[pkg.Module] = prodModule[pkg.Module] || pkg.Class != TestOnly
+		testModule[pkg.Module] = testModule[pkg.Module] || pkg.Class != Prod
+	}
+	for _, mod := range report.Modules {
+		mod.Class = classify(prodModule[mod.Path], testModule[mod.Path])
+	}
+	sort.Slice(report.Modules, func(i, k int) bool {
+		return report.Modules[i].Path < report.Modules[k].Path
+	})
+
+	return report, errs
+}
+
+func hasTestFiles(p *packages.Package) bool {
+	for _, file := range p.GoFiles {
+		if strings.HasSuffix(file, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package testdeps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkgset"
+)
+
+func TestAnalyze(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app_test.go")
+	err := os.WriteFile(testFile, []byte("package app\n\nimport (\n\t\"example.com/assert\"\n\t\"example.com/lib\"\n)\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	module := &packages.Module{Path: "example.com/deps"}
+	pkg := func(id, pkgPath string, files ...string) *packages.Package {
+		return &packages.Package{ID: id, PkgPath: pkgPath, GoFiles: files, Module: module, Imports: map[string]*packages.Package{}}
+	}
+
+	lib := pkg("example.com/lib", "example.com/lib")
+	util := pkg("example.com/util", "example.com/util")
+	assert := pkg("example.com/assert", "example.com/assert")
+	app := pkg("example.com/app", "example.com/app")
+	app.Imports["example.com/lib"] = lib
+	app.Imports["example.com/util"] = util
+	appTest := pkg("example.com/app [example.com/app.test]", "example.com/app", testFile)
+	appTest.Imports["example.com/lib"] = lib
+	appTest.Imports["example.com/util"] = util
+	appTest.Imports["example.com/assert"] = assert
+
+	prod := pkgset.NewRoot(app, lib, util)
+	test := pkgset.NewRoot(app, appTest, lib, util, assert)
+
+	report, errs := Analyze(prod, test)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	classes := map[string]Class{}
+	for _, p := range report.Packages {
+		classes[p.PkgPath] = p.Class
+	}
+	expected := map[string]Class{
+		"example.com/app":    Prod,
+		"example.com/lib":    Both,
+		"example.com/util":   Prod,
+		"example.com/assert": TestOnly,
+	}
+	for path, class := range expected {
+		if classes[path] != class {
+			t.Errorf("%v: got %v, expected %v", path, classes[path], class)
+		}
+	}
+
+	for _, p := range report.Packages {
+		if p.PkgPath != "example.com/assert" {
+			continue
+		}
+		if len(p.IntroducedBy) != 1 || p.IntroducedBy[0].File != testFile || p.IntroducedBy[0].Line != 4 {
+			t.Errorf("unexpected introduced by %v", p.IntroducedBy)
+		}
+	}
+
+	if len(report.Modules) != 1 || report.Modules[0].Class != Both {
+		t.Errorf("expected a single module used by both, got %v", report.Modules)
+	}
+}
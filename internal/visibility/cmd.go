@@ -0,0 +1,110 @@
+package visibility
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/subcommands"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgset"
+)
+
+type Command struct {
+	printStandard bool
+	json          bool
+}
+
+func (*Command) Name() string     { return "visibility" }
+func (*Command) Synopsis() string { return "Print usage of internal packages." }
+func (*Command) Usage() string {
+	return `visibility <expr>:
+	Print which packages each internal package is visible to and
+	which packages actually import it, including importers from
+	other modules, e.g. nested modules or modules in a workspace.
+
+	Additionally it prints packages that could be moved into an
+	internal directory, because all of their importers are under
+	one parent within the module.
+
+	Only importers within expr are considered, hence use an
+	expression that covers all modules, e.g. "./...".
+
+	See "help expr" for further information about expressions.
+`
+}
+
+func (cmd *Command) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&cmd.printStandard, "std", false, "print std packages")
+	f.BoolVar(&cmd.json, "json", false, "print as json")
+}
+
+func (cmd *Command) Execute(ctx context.Context, f *flag.FlagSet, _ ...any) subcommands.ExitStatus {
+	if !cmd.printStandard {
+		go pkgset.LoadStd()
+	}
+
+	expr := f.Args()
+	if len(expr) == 0 {
+		expr = []string{"./..."}
+	}
+
+	result, err := pkgset.Calc(ctx, expr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return subcommands.ExitFailure
+	}
+	if !cmd.printStandard {
+		result = pkgset.Subtract(result, pkgset.Std())
+	}
+
+	report := Analyze(pkggraph.From(result))
+
+	if cmd.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return subcommands.ExitFailure
+		}
+		return subcommands.ExitSuccess
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer func() { _ = w.Flush() }()
+
+	fmt.Fprintln(w, "INTERNAL\tVISIBLE TO\tIMPORTED BY")
+	for _, info := range report.Internal {
+		var importers []string
+		importers = append(importers, info.ImportedBy...)
+		for _, id := range info.CrossModule {
+			importers = append(importers, id+" (other module)")
+		}
+		if len(importers) == 0 {
+			importers = []string{"-"}
+		}
+
+		for i, importer := range importers {
+			if i == 0 {
+				fmt.Fprintf(w, "%v\t%v\t%v\n", info.ID, info.VisibleTo, importer)
+			} else {
+				fmt.Fprintf(w, "\t\t%v\n", importer)
+			}
+		}
+	}
+
+	if len(report.Candidates) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "CANDIDATE\tMOVE TO\tIMPORTED BY")
+		for _, candidate := range report.Candidates {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", candidate.ID, candidate.Suggested, strings.Join(candidate.ImportedBy, " "))
+		}
+	}
+
+	return subcommands.ExitSuccess
+}
@@ -0,0 +1,157 @@
+package visibility
+
+import (
+	"path"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/loov/goda/internal/pkggraph"
+	"github.com/loov/goda/internal/pkgset"
+)
+
+// InternalRoot returns the root of the tree that may import pkgPath,
+// when pkgPath is an internal package.
+//
+// Like the go command, only the last "internal" element is considered.
+func InternalRoot(pkgPath string) (root string, ok bool) {
+	switch {
+	case strings.HasSuffix(pkgPath, "/internal"):
+		return strings.TrimSuffix(pkgPath, "/internal"), true
+	case strings.Contains(pkgPath, "/internal/"):
+		return pkgPath[:strings.LastIndex(pkgPath, "/internal/")], true
+	case pkgPath == "internal" || strings.HasPrefix(pkgPath, "internal/"):
+		return "", true
+	}
+	return "", false
+}
+
+// Within returns whether pkgPath is inside the tree rooted at root.
+func Within(root, pkgPath string) bool {
+	return root == "" || pkgPath == root || strings.HasPrefix(pkgPath, root+"/")
+}
+
+// CommonParent returns the longest path that contains all of paths.
+func CommonParent(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+
+	common := paths[0]
+	for _, p := range paths[1:] {
+		for !Within(common, p) {
+			common = path.Dir(common)
+			if common == "." || common == "/" {
+				return ""
+			}
+		}
+	}
+	return common
+}
+
+// Internal describes usage of an internal package.
+type Internal struct {
+	ID     string
+	Module string `json:",omitempty"`
+	// VisibleTo is the root of the tree allowed to import the package.
+	VisibleTo string
+	// ImportedBy lists importers from the same module.
+	ImportedBy []string `json:",omitempty"`
+	// CrossModule lists importers from other modules,
+	// e.g. from nested modules or in a workspace.
+	CrossModule []string `json:",omitempty"`
+}
+
+// Candidate is a package that could be moved into internal.
+type Candidate struct {
+	ID string
+	// Parent contains all the importers of the package.
+	Parent string
+	// Suggested is the path after moving the package.
+	Suggested  string
+	ImportedBy []string
+}
+
+// Report describes visibility of packages.
+type Report struct {
+	Internal   []Internal
+	Candidates []Candidate
+}
+
+// Analyze creates a visibility report for packages in graph.
+func Analyze(graph *pkggraph.Graph) Report {
+	importedBy := map[*pkggraph.Node][]*pkggraph.Node{}
+	for _, n := range graph.Sorted {
+		if pkgset.IsTestPkg(n.Package) {
+			continue
+		}
+		for _, dep := range n.ImportsNodes {
+			importedBy[dep] = append(importedBy[dep], n)
+		}
+	}
+
+	var report Report
+	for _, n := range graph.Sorted {
+		if pkgset.IsTestPkg(n.Package) || n.Name == "main" {
+			continue
+		}
+
+		importers := importedBy[n]
+		if root, ok := InternalRoot(n.PkgPath); ok {
+			info := Internal{
+				ID:        n.ID,
+				Module:    modulePath(n.Package),
+				VisibleTo: root + "/...",
+			}
+			for _, importer := range importers {
+				if modulePath(importer.Package) != info.Module {
+					info.CrossModule = append(info.CrossModule, importer.ID)
+				} else {
+					info.ImportedBy = append(info.ImportedBy, importer.ID)
+				}
+			}
+			report.Internal = append(report.Internal, info)
+			continue
+		}
+
+		// Only packages in the main modules can be moved.
+		if len(importers) == 0 || n.Module == nil || !n.Module.Main {
+			continue
+		}
+
+		paths := make([]string, 0, len(importers))
+		ids := make([]string, 0, len(importers))
+		for _, importer := range importers {
+			paths = append(paths, importer.PkgPath)
+			ids = append(ids, importer.ID)
+		}
+		parent := CommonParent(paths)
+		if parent == "" {
+			continue
+		}
+		// When the importers are inside the package, then
+		// the internal directory must be next to the package.
+		if Within(n.PkgPath, parent) {
+			parent = path.Dir(n.PkgPath)
+		}
+		if !Within(n.Module.Path, parent) {
+			continue
+		}
+
+		report.Candidates = append(report.Candidates, Candidate{
+			ID:         n.ID,
+			Parent:     parent,
+			Suggested:  parent + "/internal/" + path.Base(n.PkgPath),
+			ImportedBy: ids,
+		})
+	}
+
+	return report
+}
+
+func modulePath(p *packages.Package) string {
+	if p.Module == nil {
+		return ""
+	}
+	return p.Module.Path
+}
@@ -0,0 +1,43 @@
+package visibility
+
+import "testing"
+
+func TestInternalRoot(t *testing.T) {
+	tests := []struct {
+		path string
+		root string
+		ok   bool
+	}{
+		{"example.com/a", "", false},
+		{"example.com/a/internal", "example.com/a", true},
+		{"example.com/a/internal/b", "example.com/a", true},
+		{"example.com/a/internal/b/internal/c", "example.com/a/internal/b", true},
+		{"example.com/a/internalx/b", "", false},
+		{"internal/cpu", "", true},
+	}
+	for _, test := range tests {
+		root, ok := InternalRoot(test.path)
+		if root != test.root || ok != test.ok {
+			t.Errorf("InternalRoot(%q) = %q, %v; expected %q, %v", test.path, root, ok, test.root, test.ok)
+		}
+	}
+}
+
+func TestCommonParent(t *testing.T) {
+	tests := []struct {
+		paths  []string
+		parent string
+	}{
+		{nil, ""},
+		{[]string{"example.com/a/b"}, "example.com/a/b"},
+		{[]string{"example.com/a/b", "example.com/a/c"}, "example.com/a"},
+		{[]string{"example.com/a/b", "example.com/a/b/c"}, "example.com/a/b"},
+		{[]string{"example.com/ab", "example.com/a"}, "example.com"},
+		{[]string{"example.com/a", "example.org/a"}, ""},
+	}
+	for _, test := range tests {
+		if parent := CommonParent(test.paths); parent != test.parent {
+			t.Errorf("CommonParent(%q) = %q; expected %q", test.paths, parent, test.parent)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"net/url"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -60,6 +61,30 @@ func (sym *Sym) MaybeSize() int64 {
 	return sym.Size
 }
 
+// Package returns the import path of the package defining the symbol.
+//
+// Type descriptors are attributed to the package of the type and
+// generic instantiations to the package of the generic declaration.
+// Symbols of the main package return "main".
+func (sym *Sym) Package() string {
+	name := strings.TrimPrefix(sym.QualifiedName, "type:")
+	name = strings.TrimLeft(name, "*[]")
+	// Type arguments may contain other packages.
+	name, _, _ = strings.Cut(name, "[")
+
+	slashPos := max(strings.LastIndexByte(name, '/'), 0)
+	pointOff := strings.IndexByte(name[slashPos:], '.')
+	if pointOff < 0 {
+		return ""
+	}
+	// The linker escapes dots in the last path element, e.g. "yaml%2ev3".
+	pkg := name[:slashPos+pointOff]
+	if unescaped, err := url.PathUnescape(pkg); err == nil {
+		return unescaped
+	}
+	return pkg
+}
+
 func ParseBinary(binary string) ([]*Sym, error) {
 	command := exec.Command("go", "tool", "nm", "-size", binary)
 
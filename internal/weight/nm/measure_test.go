@@ -34,3 +34,30 @@ func TestParseLine(t *testing.T) {
 		}
 	}
 }
+
+func TestPackage(t *testing.T) {
+	tests := []struct {
+		line string
+		pkg  string
+	}{
+		{`115d4a0        256 D time.utcLoc`, "time"},
+		{`75a6c0       4181 T github.com/loov/goda/internal/cut.(*Command).Execute`, "github.com/loov/goda/internal/cut"},
+		{`75e060        107 T github.com/loov/goda/internal/cut.(*Node).Add`, "github.com/loov/goda/internal/cut"},
+		{`1001fa0        256 R type:*github.com/loov/goda/internal/cut.Node`, "github.com/loov/goda/internal/cut"},
+		{`761d20        391 T github.com/loov/goda/internal/partition.sortedKeys[go.shape.[]*github.com/loov/goda/internal/pkggraph.Node]`, "github.com/loov/goda/internal/partition"},
+		{`761e40        120 T github.com/loov/goda/internal/pkgset.(*Set[go.shape.string]).Add`, "github.com/loov/goda/internal/pkgset"},
+		{`1001fb0         48 R type:[]github.com/loov/goda/internal/cut.Edge`, "github.com/loov/goda/internal/cut"},
+		{`5a1c20        312 T gopkg.in/yaml%2ev3.(*parser).parse`, "gopkg.in/yaml.v3"},
+		{`5a1d40         96 T example.com/m/yaml%2ev3.Big`, "example.com/m/yaml.v3"},
+		{`4c2b00        210 T main.main`, "main"},
+	}
+	for _, test := range tests {
+		sym, err := parseLine(test.line)
+		if err != nil {
+			t.Fatalf("%q parsing failed: %v", test.line, err)
+		}
+		if got := sym.Package(); got != test.pkg {
+			t.Errorf("%q: got %q, expected %q", test.line, got, test.pkg)
+		}
+	}
+}
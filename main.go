@@ -10,12 +10,23 @@ import (
 
 	"github.com/google/subcommands"
 
+	"github.com/loov/goda/internal/caps"
+	"github.com/loov/goda/internal/cgo"
+	"github.com/loov/goda/internal/check"
 	"github.com/loov/goda/internal/cut"
+	"github.com/loov/goda/internal/cycles"
+	"github.com/loov/goda/internal/dsm"
 	"github.com/loov/goda/internal/exec"
 	"github.com/loov/goda/internal/graph"
+	"github.com/loov/goda/internal/initorder"
+	"github.com/loov/goda/internal/inittrace"
 	"github.com/loov/goda/internal/list"
+	"github.com/loov/goda/internal/metrics"
+	"github.com/loov/goda/internal/partition"
 	"github.com/loov/goda/internal/pkgset"
+	"github.com/loov/goda/internal/testdeps"
 	"github.com/loov/goda/internal/tree"
+	"github.com/loov/goda/internal/visibility"
 	"github.com/loov/goda/internal/weight"
 	"github.com/loov/goda/internal/weightdiff"
 )
@@ -32,7 +43,19 @@ func main() {
 	cmds.Register(&weight.Command{}, "")
 	cmds.Register(&weightdiff.Command{}, "")
 	cmds.Register(&graph.Command{}, "")
+	cmds.Register(&graph.DiffCommand{}, "")
 	cmds.Register(&cut.Command{}, "")
+	cmds.Register(&dsm.Command{}, "")
+	cmds.Register(&cycles.Command{}, "")
+	cmds.Register(&metrics.Command{}, "")
+	cmds.Register(&partition.Command{}, "")
+	cmds.Register(&caps.Command{}, "")
+	cmds.Register(&cgo.Command{}, "")
+	cmds.Register(&check.Command{}, "")
+	cmds.Register(&initorder.Command{}, "")
+	cmds.Register(&inittrace.Command{}, "")
+	cmds.Register(&visibility.Command{}, "")
+	cmds.Register(&testdeps.Command{}, "")
 	cmds.Register(&ExprHelp{}, "")
 	cmds.Register(&FormatHelp{}, "")
 
@@ -127,10 +150,55 @@ package expressions.
 	transitive(X);
 		a transitive reduction in package dependencies
 
+	blank(X);
+		packages imported from X only for side-effects,
+		e.g. import _ "net/http/pprof"
+
+	inits(X);
+		packages from X with init functions or package-level variables
+		initialized with function calls or composite literals
+
+	cgo(X);
+		packages from X that import "C"
+
+	linkname(X);
+		packages from X that use //go:linkname directives in Go files
+
+	unsafe(X);
+		packages from X that import "unsafe", see "caps=unsafe(X)" for
+		packages that reach it via non-std dependencies
+
+	asm(X);
+		packages from X that contain assembly (.s or .S) files
+
+	cycles(X);
+		packages from X that import packages from other modules, which
+		form an import cycle between modules, including the imported
+		packages
+
 	deadcode(X);
 		packages from X that reach a dependency which disables dead code
 		elimination (e.g. reflect.Value.MethodByName)
 
+	testonly(X);
+		dependencies of X that are only needed by tests of X
+
+	deprecated(X);
+		packages from X in deprecated modules
+
+	retracted(X);
+		packages from X in modules, whose selected version is retracted
+
+	replaced(X);
+		packages from X in modules replaced by a replace directive
+
+# Capabilities:
+
+	caps=net(X):
+		packages from X that use net directly or via non-std dependencies,
+		supported capabilities: exec, files, net, plugin, reflect,
+		syscall, unsafe; see "help caps" for details
+
 # Tags and OS:
 
 	test=1(X);
@@ -193,13 +261,26 @@ and upstream statistics:
     type Node struct {
         *Package
 
-        ImportsNodes []*Node
+        ImportsNodes    []*Node
+        ImportedByNodes []*Node
+        ImportSites     []ImportSite // Locations of import specs.
 
         Stat Stat // Stats about the current node.
         Up   Stat // Stats about upstream nodes.
         Down Stat // Stats about downstream nodes.
+
+        Metrics Metrics // Coupling metrics within the package set.
     }
 
+    // Centrality within the package set.
+    func (*Node) PageRank() float64    // rank flowing from importers to imports
+    func (*Node) Betweenness() float64 // shortest import paths going through the node
+    func (*Node) Level() int           // longest import path to a package without imports
+    func (*Node) Depth() int           // longest import path from a package not imported
+
+    // Import paths, which are imported only for side-effects.
+    func (*Node) BlankImports() []string
+
     type Package struct {
         ID      string // ID is a unique identifier for a package,
         PkgPath string // PkgPath is the full import path of the package.
@@ -207,11 +288,34 @@ and upstream statistics:
     }
 
     type Module struct {
-        Path    string // module path
-        Version string // module version
-        Main    bool   // is this the main module?
+        Path    string  // module path
+        Version string  // module version
+        Main    bool    // is this the main module?
+        Replace *Module // replaced by this module
     }
 
+Additional information from the module's go.mod is available via
+ModuleInfo. Deprecations and retractions are read from the latest
+version of the module in the module cache:
+
+    func (*Node) ModuleInfo() *ModuleInfo
+
+    type ModuleInfo struct {
+        Go        string // go directive
+        Toolchain string // toolchain directive
+
+        Deprecated string   // deprecation message
+        Retracted  []string // rationales of retractions covering Version
+
+        Replaced     bool // module is replaced
+        Pseudo       bool // version is a pseudo-version
+        Incompatible bool // version has +incompatible suffix
+    }
+
+For example, to print the go directive of each module:
+
+    goda list -f "{{.Module.Path}} {{.ModuleInfo.Go}}" ./...:all
+
 This is not the full list of information about the node, however,
 this is the most useful. To see inspect the structures in depth,
 it's possible to use:
@@ -229,6 +333,8 @@ Statistics for package contains the following information:
 
         Decls  Decls
         Tokens Tokens
+        Unsafe Unsafe
+        Cgo    Cgo
     }
 
 The source information contains the following information:
@@ -255,6 +361,12 @@ binary size.
         Const int64
         Var   int64
         Other int64
+
+        Interface int64 // interface types
+        Concrete  int64 // non-interface types, excluding aliases
+
+        Init    int64 // init functions
+        InitVar int64 // variables initialized with calls or composite literals, upper bound
     }
 
     type Tokens struct {
@@ -263,6 +375,53 @@ binary size.
         Basic   int64
     }
 
+Packages that bypass the type system or the toolchain can be found using:
+
+    type Unsafe struct {
+        Imports  int64 // files importing "unsafe"
+        Pointer  int64 // unsafe.Pointer conversions
+        Linkname int64 // //go:linkname directives
+        Nosplit  int64 // //go:nosplit directives
+        Noescape int64 // //go:noescape directives
+        Asm      int64 // assembly files
+    }
+
+Native code is described by:
+
+    type Cgo struct {
+        Files      int64 // Go files importing "C"
+        Directives int64 // #cgo directives
+
+        // Native source file counts.
+        C, CXX, ObjC, Fortran, SWIG, Headers int64
+    }
+
+Coupling metrics only consider packages in the package set:
+
+    type Metrics struct {
+        Ca           int     // afferent coupling, count of importers
+        Ce           int     // efferent coupling, count of imports
+        Instability  float64 // Ce / (Ca + Ce)
+        Abstractness float64 // Interface / (Interface + Concrete)
+        Distance     float64 // distance from the main sequence |A + I - 1|
+    }
+
+Import sites describe where the package imports its dependencies:
+
+    type ImportSite struct {
+        Path   string // import path as written in the source
+        File   string
+        Line   int
+        Column int
+        Name   string // explicit package name, if any
+        Kind   string // "blank", "dot", "renamed" or ""
+        Test   bool   // whether the import is in a _test.go file
+    }
+
+As an example, to print the locations of blank imports:
+
+    goda list -f "{{range .ImportSites}}{{if eq .Kind \"blank\"}}{{.Position}} {{.Path}}{{\"\\n\"}}{{end}}{{end}}" ./...
+
 "goda cut" command additionally contains:
 
     type Node struct {